@@ -0,0 +1,146 @@
+// Command evaluate compares recommendation strategies offline on a snapshot
+// of tag and vote events, so ranking changes can be checked in CI without a
+// database or a running server.
+//
+// Take a snapshot from the database configured in config.toml:
+//
+//	go run ./cmd/evaluate -dump snapshot.json
+//
+// Then evaluate every registered strategy on it:
+//
+//	go run ./cmd/evaluate -snapshot snapshot.json -k 10
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/evaluate"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/recommend"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+)
+
+func main() {
+	defaults := config.DefaultRecommendations
+	var (
+		dump       = flag.String("dump", "", "write a snapshot of the database to this file and exit")
+		snapshot   = flag.String("snapshot", "", "snapshot file to evaluate on")
+		k          = flag.Int("k", 10, "length of each recommendation list")
+		train      = flag.Float64("train", 0.8, "share of events, oldest first, to train on")
+		cutoff     = flag.String("cutoff", "", "train on events before this RFC 3339 time instead of using -train")
+		strategies = flag.String("strategies", strings.Join(recommend.Strategies(), ","), "comma-separated strategies to run")
+		format     = flag.String("format", "table", "output format, table or json")
+		jsonOut    = flag.String("json", "", "also write the JSON report to this file")
+
+		// Defaults match the [recommendations] config
+		tagWeight       = flag.Float64("tag-weight", defaults.TagWeight, "weight of the tag profile score in blend")
+		cfWeight        = flag.Float64("cf-weight", defaults.CFWeight, "weight of the CF score in blend")
+		neighbors       = flag.Int("neighbors", defaults.Neighbors, "CF neighbors kept per creator")
+		shrinkage       = flag.Float64("shrinkage", defaults.Shrinkage, "support at which a CF similarity counts half")
+		minCoOccurrence = flag.Int("min-cooccurrence", defaults.MinCoOccurrence, "users two creators need in common to be compared")
+	)
+	flag.Parse()
+
+	if *dump != "" {
+		if err := dumpSnapshot(*dump); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	if *snapshot == "" {
+		fail(fmt.Errorf("-snapshot or -dump is required"))
+	}
+	if *k < 1 {
+		fail(fmt.Errorf("-k must be at least 1"))
+	}
+	if *format != "table" && *format != "json" {
+		fail(fmt.Errorf("-format must be table or json"))
+	}
+
+	snap, err := evaluate.ReadSnapshot(*snapshot)
+	if err != nil {
+		fail(err)
+	}
+
+	opts := evaluate.Options{
+		K:          *k,
+		Cutoff:     evaluate.Cutoff(snap.Events, *train),
+		Strategies: strings.Split(*strategies, ","),
+		Strategy: recommend.StrategyOptions{
+			CF: recommend.ItemCFOptions{
+				Neighbors:       *neighbors,
+				Shrinkage:       *shrinkage,
+				MinCoOccurrence: *minCoOccurrence,
+			},
+			TagWeight: *tagWeight,
+			CFWeight:  *cfWeight,
+		},
+	}
+	if *cutoff != "" {
+		if opts.Cutoff, err = time.Parse(time.RFC3339, *cutoff); err != nil {
+			fail(fmt.Errorf("invalid -cutoff: %w", err))
+		}
+	}
+
+	report, err := evaluate.Run(snap.Events, opts)
+	if err != nil {
+		fail(err)
+	}
+
+	if *jsonOut != "" {
+		f, err := os.Create(*jsonOut)
+		if err != nil {
+			fail(fmt.Errorf("failed to create JSON report: %w", err))
+		}
+		err = evaluate.WriteJSON(f, report)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fail(fmt.Errorf("failed to write JSON report: %w", err))
+		}
+	}
+
+	if *format == "json" {
+		err = evaluate.WriteJSON(os.Stdout, report)
+	} else {
+		err = evaluate.WriteTable(os.Stdout, report)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+// dumpSnapshot writes every event in the configured database to path
+func dumpSnapshot(path string) error {
+	logger.InitLogger()
+	config.LoadConfig()
+
+	if err := db.InitDB(); err != nil {
+		return err
+	}
+
+	events, err := db.LoadEvents(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if err := evaluate.WriteSnapshot(path, evaluate.Snapshot{TakenAt: time.Now().UTC(), Events: events}); err != nil {
+		return err
+	}
+
+	logger.Log.Info("Wrote snapshot", "path", path, "events", len(events))
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "evaluate:", err)
+	os.Exit(1)
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/MichaelWaters001/youtube-recommender/backend/internal/auth"
 	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
 	"github.com/MichaelWaters001/youtube-recommender/backend/internal/handlers"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/importer"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/refresh"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/similarity"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
 	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
 	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
 	"github.com/gin-gonic/gin"
@@ -25,16 +30,42 @@ func main() {
 		panic(fmt.Sprintf("Database initialization failed: %v", err))
 	}
 
+	// Slug and merge tags created before slugs were stored. Tags without a
+	// slug cannot be read, so the server does not start without them.
+	if err := db.BackfillTagSlugs(context.Background()); err != nil {
+		panic(fmt.Sprintf("Tag slug backfill failed: %v", err))
+	}
+
+	// Score creator tags voted on before scores were stored
+	if err := db.BackfillTagScores(context.Background()); err != nil {
+		logger.Log.Error("Failed to backfill tag scores", "error", err)
+	}
+
 	// Initialize Google OAuth
 	auth.InitAuth()
 
+	// Initialize YouTube Data API client with quota accounting and caching
+	youtube.InitClient(db.YouTubeStore{}, db.YouTubeStore{})
+
+	// Keep stored creator metadata fresh in the background. The refresher
+	// bypasses the lookup cache so it never records stale statistics.
+	refresh.Start(context.Background(), youtube.Uncached, config.AppConfig.Refresh)
+
+	// Keep the similar-creator index current in the background
+	similarity.Start(context.Background(), config.AppConfig.Similarity)
+
+	// Pick up bulk imports interrupted by a restart
+	if err := importer.Resume(context.Background(), youtube.API); err != nil {
+		logger.Log.Error("Failed to resume imports", "error", err)
+	}
+
 	// Create Gin router
 	r := gin.Default()
 
 	// CORS Middleware
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173"},
-		AllowMethods:     []string{"GET", "POST", "DELETE"},
+		AllowMethods:     []string{"GET", "POST", "PATCH", "DELETE"},
 		AllowHeaders:     []string{"Authorization", "Content-Type"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
@@ -47,8 +78,18 @@ func main() {
 	r.POST("/auth/logout", auth.Logout)
 
 	// Public routes for viewing information
+	r.GET("/creators", handlers.ListCreators)
+	r.GET("/creators/by-handle/:handle", handlers.GetCreatorByHandle)
 	r.GET("/creators/:id", handlers.GetCreator)
-	r.GET("/creators/:id/tags", handlers.GetTags)
+	r.GET("/creators/:id/tags", auth.OptionalAuth(), handlers.GetTags)
+	r.GET("/creators/:id/similar", handlers.GetSimilarCreators)
+	r.GET("/creators/:id/stats", handlers.GetCreatorStats)
+	r.GET("/creators/:id/videos", handlers.GetVideos)
+	r.GET("/tags/suggest", handlers.SuggestTags)
+	r.GET("/tags/roots", handlers.GetRootTags)
+	r.GET("/tags/:id", handlers.GetTag)
+	r.GET("/tags/:id/descendants", handlers.GetTagDescendants)
+	r.GET("/tags/:id/aliases", handlers.GetTagAliases)
 	r.GET("/search", handlers.SearchCreators) // Allow public searching
 
 	// Protected routes (require JWT for adding/modifying data)
@@ -56,11 +97,35 @@ func main() {
 	protected.Use(auth.AuthMiddleware())
 	{
 		protected.POST("/creators", handlers.AddCreator)
+		protected.POST("/creators/import", handlers.ImportCreators)
+		protected.GET("/imports/:id", handlers.GetImport)
+		protected.GET("/me/recommendations", handlers.GetRecommendations)
 		protected.POST("/creators/:id/tags", handlers.AddTag)
 		protected.POST("/votes", handlers.VoteTag)
 		protected.DELETE("/votes/:creator_tag_id", handlers.RemoveVote)
 	}
 
+	// Moderation routes
+	moderation := r.Group("/")
+	moderation.Use(auth.AuthMiddleware(), auth.RequireRole(db.RoleModerator, db.RoleAdmin))
+	{
+		moderation.PATCH("/creators/:id", handlers.UpdateCreator)
+		moderation.DELETE("/creators/:id", handlers.DeleteCreator)
+		moderation.POST("/creators/:id/restore", handlers.RestoreCreator)
+		moderation.POST("/creators/:id/merge", handlers.MergeCreators)
+		moderation.POST("/tags/:id/aliases", handlers.CreateTagAlias)
+		moderation.DELETE("/tags/:id/aliases/:alias", handlers.DeleteTagAlias)
+		moderation.POST("/tags/:id/parents", handlers.AddTagParent)
+		moderation.DELETE("/tags/:id/parents/:parent_id", handlers.RemoveTagParent)
+	}
+
+	// Admin routes
+	admin := r.Group("/admin")
+	admin.Use(auth.AuthMiddleware(), auth.RequireRole(db.RoleAdmin))
+	{
+		admin.GET("/quota", handlers.GetQuotaUsage)
+	}
+
 	// Start server
 	port := config.AppConfig.Server.Port
 	logger.Log.Info("Starting server", "port", port)
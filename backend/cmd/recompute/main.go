@@ -0,0 +1,56 @@
+// Command recompute rebuilds the item-based collaborative filtering neighbors
+// used by personalized recommendations. Run it on a schedule, e.g. nightly.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/recommend"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+)
+
+func main() {
+	logger.InitLogger()
+	config.LoadConfig()
+
+	if err := db.InitDB(); err != nil {
+		panic(fmt.Sprintf("Database initialization failed: %v", err))
+	}
+
+	if err := run(context.Background(), config.AppConfig.Recommendations); err != nil {
+		logger.Log.Error("Recompute failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, cfg config.RecommendationsConfig) error {
+	start := time.Now()
+
+	interactions, err := db.LoadInteractions(ctx)
+	if err != nil {
+		return err
+	}
+
+	neighbors := recommend.ItemSimilarities(interactions, recommend.ItemCFOptions{
+		Neighbors:       cfg.Neighbors,
+		Shrinkage:       cfg.Shrinkage,
+		MinCoOccurrence: cfg.MinCoOccurrence,
+	})
+
+	stored, err := db.ReplaceCFNeighbors(ctx, neighbors)
+	if err != nil {
+		return err
+	}
+
+	logger.Log.Info("Recomputed CF neighbors",
+		"interactions", len(interactions),
+		"creators", len(neighbors),
+		"neighbors", stored,
+		"duration", time.Since(start))
+	return nil
+}
@@ -1,10 +1,14 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -34,6 +38,55 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuth sets user_id like AuthMiddleware when a valid JWT is present,
+// and lets the request through anonymously otherwise
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tokenString := extractToken(c); tokenString != "" {
+			if claims, err := validateJWT(tokenString); err == nil {
+				if userID, ok := claims["user_id"].(float64); ok {
+					c.Set("user_id", int(userID))
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireRole only lets users with one of the given roles through.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		role, err := db.GetUserRole(ctx, userID.(int))
+		if err != nil {
+			logger.Log.Error("Failed to fetch user role", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			c.Abort()
+			return
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		c.Abort()
+	}
+}
+
 // Extracts JWT from Authorization header
 func extractToken(c *gin.Context) string {
 	authHeader := c.GetHeader("Authorization")
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/recommend"
+	"github.com/jackc/pgx/v5"
+)
+
+// LoadInteractions returns every user's positive interactions with live creators
+func LoadInteractions(ctx context.Context) ([]recommend.Interaction, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT user_id, creator_id, weight FROM user_creator_interactions
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load interactions: %w", err)
+	}
+	defer rows.Close()
+
+	var interactions []recommend.Interaction
+	for rows.Next() {
+		var in recommend.Interaction
+		if err := rows.Scan(&in.UserID, &in.CreatorID, &in.Weight); err != nil {
+			return nil, fmt.Errorf("failed to scan interaction: %w", err)
+		}
+		interactions = append(interactions, in)
+	}
+
+	return interactions, rows.Err()
+}
+
+// ReplaceCFNeighbors replaces all collaborative filtering neighbors in one
+// transaction, so readers see either the old table or the new one
+func ReplaceCFNeighbors(ctx context.Context, neighbors map[int][]recommend.Neighbor) (int64, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM creator_cf_neighbors`); err != nil {
+		return 0, fmt.Errorf("failed to clear CF neighbors: %w", err)
+	}
+
+	var rows [][]interface{}
+	for creatorID, list := range neighbors {
+		for _, n := range list {
+			rows = append(rows, []interface{}{creatorID, n.CreatorID, n.Score, n.Support})
+		}
+	}
+
+	copied, err := tx.CopyFrom(ctx, pgx.Identifier{"creator_cf_neighbors"},
+		[]string{"creator_id", "neighbor_id", "score", "support"}, pgx.CopyFromRows(rows))
+	if err != nil {
+		return 0, fmt.Errorf("failed to store CF neighbors: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit CF neighbors: %w", err)
+	}
+
+	return copied, nil
+}
+
+// LoadEvents returns every tag submission and vote on live creators, for
+// offline evaluation. Votes are dated by their last change.
+func LoadEvents(ctx context.Context) ([]recommend.Event, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT ct.user_id, ct.creator_id, ct.tag_id, $1::text, ct.created_at
+		FROM creator_tags ct
+		JOIN creators c ON c.id = ct.creator_id
+		WHERE c.deleted_at IS NULL
+		UNION ALL
+		SELECT v.user_id, ct.creator_id, ct.tag_id,
+			CASE WHEN v.vote_type = 1 THEN $2::text ELSE $3::text END, v.updated_at
+		FROM votes v
+		JOIN creator_tags ct ON ct.id = v.creator_tag_id
+		JOIN creators c ON c.id = ct.creator_id
+		WHERE c.deleted_at IS NULL
+	`, recommend.EventTagged, recommend.EventUpvote, recommend.EventDownvote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []recommend.Event
+	for rows.Next() {
+		var e recommend.Event
+		if err := rows.Scan(&e.UserID, &e.CreatorID, &e.TagID, &e.Kind, &e.At); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,428 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Creator statuses
+const (
+	CreatorActive      = "active"
+	CreatorUnavailable = "unavailable" // Channel was deleted or terminated on YouTube
+)
+
+// Creator is a stored YouTube creator
+type Creator struct {
+	ID                int        `json:"id"`
+	YouTubeID         string     `json:"youtube_id"`
+	YouTubeHandle     string     `json:"youtube_handle"`
+	Name              string     `json:"name"`
+	Description       string     `json:"description"`
+	ThumbnailURL      string     `json:"thumbnail_url"`
+	BannerURL         string     `json:"banner_url"`
+	Country           string     `json:"country"`
+	Keywords          string     `json:"keywords"`
+	UploadsPlaylistID string     `json:"uploads_playlist_id"`
+	SubscriberCount   *int64     `json:"subscriber_count"`
+	Status            string     `json:"status"`
+	LastRefreshedAt   *time.Time `json:"last_refreshed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+	MergedIntoID      *int       `json:"merged_into_id,omitempty"`
+}
+
+// creatorColumns selects the fields scanned by scanCreator
+const creatorColumns = `id, youtube_id, COALESCE(youtube_handle, ''), name, COALESCE(description, ''),
+	COALESCE(thumbnail_url, ''), COALESCE(banner_url, ''), COALESCE(country, ''), COALESCE(keywords, ''),
+	COALESCE(uploads_playlist_id, ''), subscriber_count, status, last_refreshed_at, created_at,
+	deleted_at, merged_into_id`
+
+func scanCreator(row pgx.Row, extra ...interface{}) (Creator, error) {
+	var c Creator
+	dest := append([]interface{}{
+		&c.ID, &c.YouTubeID, &c.YouTubeHandle, &c.Name, &c.Description,
+		&c.ThumbnailURL, &c.BannerURL, &c.Country, &c.Keywords, &c.UploadsPlaylistID,
+		&c.SubscriberCount, &c.Status, &c.LastRefreshedAt, &c.CreatedAt,
+		&c.DeletedAt, &c.MergedIntoID,
+	}, extra...)
+	err := row.Scan(dest...)
+	return c, err
+}
+
+// AddCreator upserts a creator keyed on its YouTube channel ID. An existing
+// creator gets its handle, name, description and branding refreshed. The
+// returned bool reports whether a new row was inserted. Channels without a
+// handle are stored with a NULL handle. A handle still stored for another
+// channel is taken from it, since YouTube only hands out released handles.
+// ErrConflict is only returned when a concurrent write stores the same handle
+// for another channel before this one commits.
+// A soft-deleted creator is refreshed but stays deleted; callers check DeletedAt.
+func AddCreator(ctx context.Context, creator Creator) (Creator, bool, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return Creator{}, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := releaseHandle(ctx, tx, creator.YouTubeID, creator.YouTubeHandle); err != nil {
+		return Creator{}, false, err
+	}
+
+	var inserted bool
+	stored, err := scanCreator(tx.QueryRow(ctx, `
+		INSERT INTO creators (youtube_handle, youtube_id, name, description,
+			thumbnail_url, banner_url, country, keywords, uploads_playlist_id, last_refreshed_at)
+		VALUES (NULLIF($1, ''), $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), now())
+		ON CONFLICT (youtube_id) DO UPDATE SET
+			youtube_handle = EXCLUDED.youtube_handle,
+			name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			thumbnail_url = EXCLUDED.thumbnail_url,
+			banner_url = EXCLUDED.banner_url,
+			country = EXCLUDED.country,
+			keywords = EXCLUDED.keywords,
+			uploads_playlist_id = EXCLUDED.uploads_playlist_id,
+			status = 'active',
+			last_refreshed_at = now()
+		RETURNING `+creatorColumns+`, (xmax = 0)
+	`, creator.YouTubeHandle, creator.YouTubeID, creator.Name, creator.Description,
+		creator.ThumbnailURL, creator.BannerURL, creator.Country, creator.Keywords, creator.UploadsPlaylistID), &inserted)
+
+	if err != nil {
+		return Creator{}, false, wrapErr("failed to add creator", err)
+	}
+
+	if err := recordHandle(ctx, tx, stored.ID, stored.YouTubeHandle); err != nil {
+		return Creator{}, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Creator{}, false, fmt.Errorf("failed to commit creator: %w", err)
+	}
+
+	return stored, inserted, nil
+}
+
+// GetCreator fetches a creator that has not been deleted
+func GetCreator(ctx context.Context, id int) (Creator, error) {
+	creator, err := scanCreator(DB.QueryRow(ctx, `
+		SELECT `+creatorColumns+`
+		FROM creators
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Creator{}, fmt.Errorf("creator %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return Creator{}, fmt.Errorf("failed to fetch creator: %w", err)
+	}
+
+	return creator, nil
+}
+
+// ListCreatorsToRefresh returns up to limit live creators with an ID above
+// afterID that have not been refreshed since staleBefore, ordered by ID
+func ListCreatorsToRefresh(ctx context.Context, staleBefore time.Time, afterID, limit int) ([]Creator, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT `+creatorColumns+`
+		FROM creators
+		WHERE id > $1 AND deleted_at IS NULL
+			AND (last_refreshed_at IS NULL OR last_refreshed_at < $2)
+		ORDER BY id
+		LIMIT $3
+	`, afterID, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list creators to refresh: %w", err)
+	}
+	defer rows.Close()
+
+	var creators []Creator
+	for rows.Next() {
+		creator, err := scanCreator(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan creator row: %w", err)
+		}
+		creators = append(creators, creator)
+	}
+
+	return creators, rows.Err()
+}
+
+// RefreshCreator updates the YouTube metadata of a creator identified by its
+// channel ID, marks it active and freshly refreshed, and returns its ID.
+// Handle changes are recorded in the handle history.
+func RefreshCreator(ctx context.Context, creator Creator) (int, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := releaseHandle(ctx, tx, creator.YouTubeID, creator.YouTubeHandle); err != nil {
+		return 0, err
+	}
+
+	var creatorID int
+	err = tx.QueryRow(ctx, `
+		UPDATE creators SET
+			youtube_handle = NULLIF($2, ''),
+			name = $3,
+			description = $4,
+			thumbnail_url = NULLIF($5, ''),
+			banner_url = NULLIF($6, ''),
+			country = NULLIF($7, ''),
+			keywords = NULLIF($8, ''),
+			uploads_playlist_id = NULLIF($9, ''),
+			status = 'active',
+			last_refreshed_at = now()
+		WHERE youtube_id = $1
+		RETURNING id
+	`, creator.YouTubeID, creator.YouTubeHandle, creator.Name, creator.Description,
+		creator.ThumbnailURL, creator.BannerURL, creator.Country, creator.Keywords, creator.UploadsPlaylistID).Scan(&creatorID)
+	if err != nil {
+		return 0, wrapErr("failed to refresh creator", err)
+	}
+
+	if err := recordHandle(ctx, tx, creatorID, creator.YouTubeHandle); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit creator refresh: %w", err)
+	}
+
+	return creatorID, nil
+}
+
+// MarkCreatorsUnavailable flags creators whose channels no longer exist on YouTube
+func MarkCreatorsUnavailable(ctx context.Context, youtubeIDs []string) error {
+	_, err := DB.Exec(ctx, `
+		UPDATE creators SET status = 'unavailable', last_refreshed_at = now()
+		WHERE youtube_id = ANY($1)
+	`, youtubeIDs)
+	if err != nil {
+		return fmt.Errorf("failed to mark creators unavailable: %w", err)
+	}
+
+	return nil
+}
+
+// MarkCreatorRefreshFailed records a failed refresh attempt as the creator's
+// last refresh, so it waits a full interval before it is retried instead of
+// being picked up again on every poll
+func MarkCreatorRefreshFailed(ctx context.Context, youtubeID string) error {
+	_, err := DB.Exec(ctx, `
+		UPDATE creators SET last_refreshed_at = now()
+		WHERE youtube_id = $1
+	`, youtubeID)
+	if err != nil {
+		return fmt.Errorf("failed to record refresh attempt: %w", err)
+	}
+
+	return nil
+}
+
+// Sort orders accepted by ListCreators
+const (
+	SortByName        = "name"
+	SortByCreatedAt   = "created_at"
+	SortByScore       = "score"
+	SortBySubscribers = "subscribers"
+)
+
+// creatorSortKeys maps each sort order to its SQL expression and type.
+// Hidden subscriber counts sort as -1 so they stay comparable in keyset pagination.
+var creatorSortKeys = map[string]struct{ expr, sqlType string }{
+	SortByName:        {"name", "text"},
+	SortByCreatedAt:   {"created_at", "timestamptz"},
+	SortByScore:       {"score", "bigint"},
+	SortBySubscribers: {"COALESCE(subscriber_count, -1)", "bigint"},
+}
+
+// ValidCreatorSort reports whether sort is accepted by ListCreators
+func ValidCreatorSort(sort string) bool {
+	_, ok := creatorSortKeys[sort]
+	return ok
+}
+
+// CreatorCursor marks a position in a creator listing: the sort key of the
+// last row, as text, and its ID as the tie-breaker
+type CreatorCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"i"`
+}
+
+// CreatorListOptions controls ListCreators
+type CreatorListOptions struct {
+	Sort     string // One of the SortBy constants
+	Desc     bool
+	Tag      string // Slug or alias of the tag to filter by, if set
+	MinScore *int   // Minimum vote score of Tag, or of all tags combined when Tag is empty
+	Limit    int
+	After    *CreatorCursor
+}
+
+// CreatorListItem is a creator with its combined tag vote score
+type CreatorListItem struct {
+	Creator
+	Score int64 `json:"score"`
+}
+
+// ListCreators returns a page of creators using keyset pagination.
+// The returned cursor is nil on the last page.
+func ListCreators(ctx context.Context, opts CreatorListOptions) ([]CreatorListItem, *CreatorCursor, error) {
+	key, ok := creatorSortKeys[opts.Sort]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown sort order %q", opts.Sort)
+	}
+
+	direction, comparison := "ASC", ">"
+	if opts.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	args := []interface{}{opts.Tag, opts.MinScore, opts.Limit + 1}
+	keyset := ""
+	if opts.After != nil {
+		args = append(args, opts.After.Value, opts.After.ID)
+		keyset = fmt.Sprintf("AND (%s, id) %s ($4::%s, $5)", key.expr, comparison, key.sqlType)
+	}
+
+	// The score is computed per row as rows are read in index order, so
+	// sorting by name, date or subscribers only scores the rows on the page.
+	// Sorting or filtering by score has to score every candidate.
+	query := fmt.Sprintf(`
+		SELECT %s, score, (%s)::text
+		FROM creators c
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(SUM(v.vote_type), 0) AS score
+			FROM creator_tags ct
+			JOIN votes v ON v.creator_tag_id = ct.id
+			JOIN tags t ON t.id = ct.tag_id
+			WHERE ct.creator_id = c.id AND ($1 = '' OR `+tagMatchesSlug+`)
+		) s
+		WHERE c.deleted_at IS NULL AND ($1 = '' OR EXISTS (
+				SELECT 1 FROM creator_tags ct
+				JOIN tags t ON t.id = ct.tag_id
+				WHERE ct.creator_id = c.id AND `+tagMatchesSlug+`
+			))
+			AND ($2::int IS NULL OR score >= $2) %s
+		ORDER BY %s %s, id %s
+		LIMIT $3
+	`, creatorColumns, key.expr, keyset, key.expr, direction, direction)
+
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list creators: %w", err)
+	}
+	defer rows.Close()
+
+	items := []CreatorListItem{}
+	var sortValues []string
+	for rows.Next() {
+		var item CreatorListItem
+		var sortValue string
+		item.Creator, err = scanCreator(rows, &item.Score, &sortValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan creator row: %w", err)
+		}
+		items = append(items, item)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list creators: %w", err)
+	}
+
+	if len(items) <= opts.Limit {
+		return items, nil, nil
+	}
+
+	items = items[:opts.Limit]
+	last := opts.Limit - 1
+	return items, &CreatorCursor{Sort: opts.Sort, Value: sortValues[last], ID: items[last].ID}, nil
+}
+
+// CreatorUpdate holds corrections to a creator; nil fields are left unchanged.
+// An empty YouTubeHandle clears the handle.
+type CreatorUpdate struct {
+	Name          *string
+	Description   *string
+	YouTubeHandle *string
+}
+
+// UpdateCreator applies corrections to a creator that has not been deleted.
+// Unlike YouTube data, a corrected handle never takes over another creator's.
+func UpdateCreator(ctx context.Context, id int, update CreatorUpdate) (Creator, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return Creator{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	creator, err := scanCreator(tx.QueryRow(ctx, `
+		UPDATE creators SET
+			name = COALESCE($2, name),
+			description = COALESCE($3, description),
+			youtube_handle = CASE WHEN $4::text IS NULL THEN youtube_handle ELSE NULLIF($4, '') END
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING `+creatorColumns+`
+	`, id, update.Name, update.Description, update.YouTubeHandle))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Creator{}, fmt.Errorf("creator %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return Creator{}, wrapErr("failed to update creator", err)
+	}
+
+	if update.YouTubeHandle != nil {
+		if err := recordHandle(ctx, tx, creator.ID, creator.YouTubeHandle); err != nil {
+			return Creator{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Creator{}, fmt.Errorf("failed to commit creator update: %w", err)
+	}
+
+	return creator, nil
+}
+
+// DeleteCreator soft-deletes a creator, hiding it from reads while keeping
+// its tags, votes and history for a later restore
+func DeleteCreator(ctx context.Context, id int) error {
+	tag, err := DB.Exec(ctx, `
+		UPDATE creators SET deleted_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete creator: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("creator %d: %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// RestoreCreator undoes a soft delete. Creators merged into another one
+// cannot be restored because their tags have moved.
+func RestoreCreator(ctx context.Context, id int) (Creator, error) {
+	creator, err := scanCreator(DB.QueryRow(ctx, `
+		UPDATE creators SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL AND merged_into_id IS NULL
+		RETURNING `+creatorColumns+`
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Creator{}, fmt.Errorf("deleted creator %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return Creator{}, fmt.Errorf("failed to restore creator: %w", err)
+	}
+
+	return creator, nil
+}
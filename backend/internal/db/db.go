@@ -52,7 +52,7 @@ func EnsureUser(ctx context.Context, email string) (int, error) {
 		insertErr := DB.QueryRow(ctx, "INSERT INTO users (google_id) VALUES ($1) RETURNING id", email).Scan(&userID)
 		if insertErr != nil {
 			log.Printf("Failed to insert user into DB: %v", insertErr)
-			return 0, fmt.Errorf("failed to insert user: %w", insertErr)
+			return 0, wrapErr("failed to insert user", insertErr)
 		}
 		return userID, nil
 	}
@@ -65,77 +65,14 @@ func EnsureUser(ctx context.Context, email string) (int, error) {
 	return userID, nil
 }
 
-// GetCreator fetches a creator by ID
-func GetCreator(ctx context.Context, id string) (map[string]interface{}, error) {
-	var creator struct {
-		ID          int
-		YouTubeID   string
-		Name        string
-		Description string
-	}
-
-	err := DB.QueryRow(ctx, "SELECT id, youtube_id, name, description FROM creators WHERE id = $1", id).
-		Scan(&creator.ID, &creator.YouTubeID, &creator.Name, &creator.Description)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch creator: %w", err)
-	}
-
-	return map[string]interface{}{
-		"id":          creator.ID,
-		"youtube_id":  creator.YouTubeID,
-		"name":        creator.Name,
-		"description": creator.Description,
-	}, nil
-}
-
-// SearchCreatorsByTag finds creators with a specific tag
-func SearchCreatorsByTag(ctx context.Context, tag string) ([]map[string]interface{}, error) {
-	rows, err := DB.Query(ctx, `
-		SELECT c.id, c.youtube_id, c.name, c.description
-		FROM creators c
-		JOIN creator_tags ct ON c.id = ct.creator_id
-		JOIN tags t ON ct.tag_id = t.id
-		WHERE t.name ILIKE $1
-	`, tag)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search creators: %w", err)
-	}
-	defer rows.Close()
-
-	var creators []map[string]interface{}
-	for rows.Next() {
-		var id int
-		var youtubeID, name, description string
-		if err := rows.Scan(&id, &youtubeID, &name, &description); err != nil {
-			return nil, fmt.Errorf("failed to scan creator row: %w", err)
-		}
-		creators = append(creators, map[string]interface{}{
-			"id":          id,
-			"youtube_id":  youtubeID,
-			"name":        name,
-			"description": description,
-		})
-	}
-
-	return creators, nil
-}
-
-// AddTag adds a tag to a creator only if it doesn't already exist
-func AddTag(ctx context.Context, creatorID int, tagName string, userID int) (int, error) {
-	var tagID int
-
-	// Check if the tag exists in the tags table
-	err := DB.QueryRow(ctx, "SELECT id FROM tags WHERE name = $1", tagName).Scan(&tagID)
+// AddTag adds a tag to a creator only if it doesn't already exist. The tag is
+// matched on its normalized slug or an alias, creating it with name when it
+// is new, and the stored name of the canonical tag is returned along with the
+// creator tag ID.
+func AddTag(ctx context.Context, creatorID int, name, slug string, userID int) (int, string, error) {
+	tagID, tagName, err := findOrCreateTag(ctx, name, slug)
 	if err != nil {
-		// If tag does not exist, insert it
-		if errors.Is(err, pgx.ErrNoRows) {
-			insertErr := DB.QueryRow(ctx, "INSERT INTO tags (name) VALUES ($1) RETURNING id", tagName).Scan(&tagID)
-			if insertErr != nil {
-				return 0, fmt.Errorf("failed to insert tag: %w", insertErr)
-			}
-		} else {
-			return 0, fmt.Errorf("failed to check tag: %w", err)
-		}
+		return 0, "", err
 	}
 
 	// Check if the tag is already assigned to this creator
@@ -146,10 +83,10 @@ func AddTag(ctx context.Context, creatorID int, tagName string, userID int) (int
 
 	if err == nil {
 		// Tag already exists for this creator
-		return 0, fmt.Errorf("tag '%s' is already assigned to this creator", tagName)
+		return 0, "", fmt.Errorf("tag '%s' is already assigned to this creator: %w", tagName, ErrConflict)
 	} else if !errors.Is(err, pgx.ErrNoRows) {
 		// Other error
-		return 0, fmt.Errorf("failed to check existing creator tag: %w", err)
+		return 0, "", fmt.Errorf("failed to check existing creator tag: %w", err)
 	}
 
 	// Insert into creator_tags
@@ -161,80 +98,135 @@ func AddTag(ctx context.Context, creatorID int, tagName string, userID int) (int
 	`, creatorID, tagID, userID).Scan(&creatorTagID)
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to add tag: %w", err)
+		return 0, "", wrapErr("failed to add tag", err)
 	}
 
-	return creatorTagID, nil
+	return creatorTagID, tagName, nil
 }
 
-// VoteTag adds or updates a user's vote for a tag
+// VoteTag adds or updates a user's vote for a tag and refreshes the tag's score
 func VoteTag(ctx context.Context, userID int, creatorTagID int, voteType int) error {
-	_, err := DB.Exec(ctx, `
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockCreatorTag(ctx, tx, creatorTagID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
 		INSERT INTO votes (user_id, creator_tag_id, vote_type)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (user_id, creator_tag_id)
-		DO UPDATE SET vote_type = EXCLUDED.vote_type
+		DO UPDATE SET vote_type = EXCLUDED.vote_type, updated_at = now()
 	`, userID, creatorTagID, voteType)
 
 	if err != nil {
-		return fmt.Errorf("failed to vote on tag: %w", err)
+		return wrapErr("failed to vote on tag", err)
 	}
 
-	return nil
-}
-
-// AddCreator inserts a new creator with both YouTube handle and channel ID
-func AddCreator(ctx context.Context, youtubeHandle, channelID, name, description string) (int, error) {
-	var creatorID int
-	err := DB.QueryRow(ctx, `
-		INSERT INTO creators (youtube_handle, youtube_id, name, description)
-		VALUES ($1, $2, $3, $4) RETURNING id
-	`, youtubeHandle, channelID, name, description).Scan(&creatorID)
+	if err := refreshTagScore(ctx, tx, creatorTagID); err != nil {
+		return err
+	}
 
-	if err != nil {
-		return 0, fmt.Errorf("failed to add creator: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit vote: %w", err)
 	}
 
-	return creatorID, nil
+	return nil
 }
 
-// GetTags retrieves all tags associated with a given creator
-func GetTags(ctx context.Context, creatorID int) ([]map[string]interface{}, error) {
+// CreatorTag is a tag on a creator with its vote tally
+type CreatorTag struct {
+	CreatorTagID int       `json:"creator_tag_id"`
+	TagID        int       `json:"tag_id"`
+	Name         string    `json:"name"`
+	Slug         string    `json:"slug"`
+	SubmittedBy  int       `json:"submitted_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	Upvotes      int       `json:"upvotes"`
+	Downvotes    int       `json:"downvotes"`
+	MyVote       *int      `json:"my_vote,omitempty"` // Set when the caller is known and has voted
+}
+
+// GetTags retrieves all tags associated with a given creator with their vote
+// counts. With a viewerID, each tag carries that user's vote.
+func GetTags(ctx context.Context, creatorID int, viewerID *int) ([]CreatorTag, error) {
 	rows, err := DB.Query(ctx, `
-		SELECT t.id, t.name, ct.user_id
+		SELECT ct.id, t.id, t.name, t.slug, ct.user_id, ct.created_at,
+			count(v.id) FILTER (WHERE v.vote_type = 1),
+			count(v.id) FILTER (WHERE v.vote_type = -1),
+			max(v.vote_type) FILTER (WHERE v.user_id = $2)
 		FROM creator_tags ct
 		JOIN tags t ON ct.tag_id = t.id
+		LEFT JOIN votes v ON v.creator_tag_id = ct.id
 		WHERE ct.creator_id = $1
-	`, creatorID)
+		GROUP BY ct.id, t.id
+	`, creatorID, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch tags: %w", err)
 	}
 	defer rows.Close()
 
-	var tags []map[string]interface{}
+	tags := []CreatorTag{}
 	for rows.Next() {
-		var tagID int
-		var tagName string
-		var userID int
-		if err := rows.Scan(&tagID, &tagName, &userID); err != nil {
+		var t CreatorTag
+		if err := rows.Scan(&t.CreatorTagID, &t.TagID, &t.Name, &t.Slug, &t.SubmittedBy, &t.CreatedAt,
+			&t.Upvotes, &t.Downvotes, &t.MyVote); err != nil {
 			return nil, fmt.Errorf("failed to scan tag row: %w", err)
 		}
-		tags = append(tags, map[string]interface{}{
-			"id":      tagID,
-			"name":    tagName,
-			"user_id": userID,
-		})
+		tags = append(tags, t)
 	}
 
-	return tags, nil
+	return tags, rows.Err()
 }
 
-// RemoveVote deletes a user's vote for a specific tag
+// RemoveVote deletes a user's vote for a specific tag and refreshes the tag's score
 func RemoveVote(ctx context.Context, userID int, creatorTagID int) error {
-	_, err := DB.Exec(ctx, "DELETE FROM votes WHERE user_id = $1 AND creator_tag_id = $2", userID, creatorTagID)
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockCreatorTag(ctx, tx, creatorTagID); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, "DELETE FROM votes WHERE user_id = $1 AND creator_tag_id = $2", userID, creatorTagID)
 	if err != nil {
 		return fmt.Errorf("failed to remove vote: %w", err)
 	}
 
+	if tag.RowsAffected() > 0 {
+		if err := refreshTagScore(ctx, tx, creatorTagID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit vote removal: %w", err)
+	}
+
 	return nil
 }
+
+// User roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// GetUserRole returns the role of a user
+func GetUserRole(ctx context.Context, userID int) (string, error) {
+	var role string
+	err := DB.QueryRow(ctx, "SELECT role FROM users WHERE id = $1", userID).Scan(&role)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch user role: %w", err)
+	}
+
+	return role, nil
+}
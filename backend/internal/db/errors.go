@@ -0,0 +1,31 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrConflict is returned when a write violates a unique constraint
+var ErrConflict = errors.New("record already exists")
+
+// ErrNotFound is returned when a record to read or modify does not exist
+var ErrNotFound = errors.New("record not found")
+
+// uniqueViolation is the Postgres SQLSTATE for unique_violation
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err was caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// wrapErr wraps a query error with context, marking unique violations as ErrConflict
+func wrapErr(msg string, err error) error {
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w: %w", msg, ErrConflict, err)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// How GetCreatorByHandle matched a handle
+const (
+	HandleCurrent  = "current"  // The creator holds the handle now
+	HandlePrevious = "previous" // The creator used the handle before changing it
+	HandleMerged   = "merged"   // The handle belonged to a duplicate merged into the creator
+)
+
+// releaseHandle takes handle away from any creator other than the channel
+// youtubeID. YouTube lets a released handle be claimed by another channel,
+// and the previous holder picks up its new handle on its next refresh.
+func releaseHandle(ctx context.Context, tx pgx.Tx, youtubeID, handle string) error {
+	if handle == "" {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE creators SET youtube_handle = NULL
+		WHERE lower(youtube_handle) = lower($1) AND youtube_id <> $2
+	`, handle, youtubeID)
+	if err != nil {
+		return fmt.Errorf("failed to release handle: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE creator_handles h SET released_at = now()
+		FROM creators c
+		WHERE c.id = h.creator_id AND c.youtube_id <> $2
+			AND lower(h.handle) = lower($1) AND h.released_at IS NULL
+	`, handle, youtubeID)
+	if err != nil {
+		return fmt.Errorf("failed to release handle history: %w", err)
+	}
+
+	return nil
+}
+
+// recordHandle notes that creatorID currently holds handle, releasing any
+// other handle it held. An empty handle only releases.
+func recordHandle(ctx context.Context, tx pgx.Tx, creatorID int, handle string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE creator_handles SET released_at = now()
+		WHERE creator_id = $1 AND released_at IS NULL AND lower(handle) <> lower($2)
+	`, creatorID, handle)
+	if err != nil {
+		return fmt.Errorf("failed to release previous handle: %w", err)
+	}
+
+	if handle == "" {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO creator_handles (creator_id, handle)
+		VALUES ($1, $2)
+		ON CONFLICT (creator_id, lower(handle)) DO UPDATE SET
+			handle = EXCLUDED.handle,
+			last_seen_at = now(),
+			released_at = NULL
+	`, creatorID, handle)
+	if err != nil {
+		return fmt.Errorf("failed to record handle: %w", err)
+	}
+
+	return nil
+}
+
+// GetCreatorByHandle finds the creator holding handle, falling back to the
+// creator that most recently used it and following merges. The returned
+// match is one of the Handle constants.
+func GetCreatorByHandle(ctx context.Context, handle string) (Creator, string, error) {
+	creator, err := scanCreator(DB.QueryRow(ctx, `
+		SELECT `+creatorColumns+`
+		FROM creators
+		WHERE lower(youtube_handle) = lower($1) AND deleted_at IS NULL
+	`, handle))
+	if err == nil {
+		return creator, HandleCurrent, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Creator{}, "", fmt.Errorf("failed to fetch creator by handle: %w", err)
+	}
+
+	// Merges repoint earlier merges, so one hop reaches the surviving creator
+	var merged bool
+	creator, err = scanCreator(DB.QueryRow(ctx, `
+		SELECT `+creatorColumns+`, m.merged
+		FROM creators
+		JOIN (
+			SELECT COALESCE(d.merged_into_id, d.id) AS target_id,
+				d.merged_into_id IS NOT NULL AS merged, h.last_seen_at
+			FROM creator_handles h
+			JOIN creators d ON d.id = h.creator_id
+			WHERE lower(h.handle) = lower($1)
+		) m ON m.target_id = creators.id
+		WHERE deleted_at IS NULL
+		ORDER BY m.last_seen_at DESC
+		LIMIT 1
+	`, handle), &merged)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Creator{}, "", fmt.Errorf("handle %s: %w", handle, ErrNotFound)
+	}
+	if err != nil {
+		return Creator{}, "", fmt.Errorf("failed to look up handle history: %w", err)
+	}
+
+	if merged {
+		return creator, HandleMerged, nil
+	}
+	return creator, HandlePrevious, nil
+}
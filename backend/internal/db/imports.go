@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Import job statuses
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// Import row statuses
+const (
+	RowPending  = "pending"
+	RowAdded    = "added"
+	RowExisting = "existing"
+	RowNotFound = "not_found"
+	RowFailed   = "failed"
+)
+
+// Import is a bulk creator import job
+type Import struct {
+	ID         int            `json:"id"`
+	UserID     int            `json:"user_id"`
+	Format     string         `json:"format"`
+	Status     string         `json:"status"`
+	CreatedAt  time.Time      `json:"created_at"`
+	FinishedAt *time.Time     `json:"finished_at"`
+	Counts     map[string]int `json:"counts"`
+	Rows       []ImportRow    `json:"rows"`
+}
+
+// ImportRow is the outcome of importing one channel
+type ImportRow struct {
+	ID        int    `json:"-"`
+	RowNumber int    `json:"row"`
+	Input     string `json:"input"`
+	Status    string `json:"status"`
+	CreatorID *int   `json:"creator_id"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CreateImport stores a pending import job with one row per input
+func CreateImport(ctx context.Context, userID int, format string, inputs []string) (int, error) {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var importID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO imports (user_id, format) VALUES ($1, $2) RETURNING id
+	`, userID, format).Scan(&importID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create import: %w", err)
+	}
+
+	rows := make([][]interface{}, len(inputs))
+	for i, input := range inputs {
+		rows[i] = []interface{}{importID, i + 1, input}
+	}
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"import_rows"}, []string{"import_id", "row_number", "input"}, pgx.CopyFromRows(rows))
+	if err != nil {
+		return 0, fmt.Errorf("failed to store import rows: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	return importID, nil
+}
+
+// GetImport fetches an import job with all of its rows and per-status counts
+func GetImport(ctx context.Context, importID int) (Import, error) {
+	var job Import
+	err := DB.QueryRow(ctx, `
+		SELECT id, user_id, format, status, created_at, finished_at FROM imports WHERE id = $1
+	`, importID).Scan(&job.ID, &job.UserID, &job.Format, &job.Status, &job.CreatedAt, &job.FinishedAt)
+	if err != nil {
+		return Import{}, fmt.Errorf("failed to fetch import: %w", err)
+	}
+
+	rows, err := DB.Query(ctx, `
+		SELECT id, row_number, input, status, creator_id, COALESCE(error, '')
+		FROM import_rows WHERE import_id = $1 ORDER BY row_number
+	`, importID)
+	if err != nil {
+		return Import{}, fmt.Errorf("failed to fetch import rows: %w", err)
+	}
+	defer rows.Close()
+
+	job.Counts = map[string]int{}
+	job.Rows = []ImportRow{}
+	for rows.Next() {
+		var row ImportRow
+		if err := rows.Scan(&row.ID, &row.RowNumber, &row.Input, &row.Status, &row.CreatorID, &row.Error); err != nil {
+			return Import{}, fmt.Errorf("failed to scan import row: %w", err)
+		}
+		job.Counts[row.Status]++
+		job.Rows = append(job.Rows, row)
+	}
+
+	return job, rows.Err()
+}
+
+// PendingImportRows returns the rows of an import that have not been processed yet
+func PendingImportRows(ctx context.Context, importID int) ([]ImportRow, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT id, row_number, input, status, creator_id, COALESCE(error, '')
+		FROM import_rows WHERE import_id = $1 AND status = 'pending' ORDER BY row_number
+	`, importID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending import rows: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []ImportRow
+	for rows.Next() {
+		var row ImportRow
+		if err := rows.Scan(&row.ID, &row.RowNumber, &row.Input, &row.Status, &row.CreatorID, &row.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan import row: %w", err)
+		}
+		pending = append(pending, row)
+	}
+
+	return pending, rows.Err()
+}
+
+// UpdateImportRow records the outcome of importing one row
+func UpdateImportRow(ctx context.Context, rowID int, status string, creatorID *int, errMsg string) error {
+	_, err := DB.Exec(ctx, `
+		UPDATE import_rows SET status = $2, creator_id = $3, error = NULLIF($4, '') WHERE id = $1
+	`, rowID, status, creatorID, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to update import row: %w", err)
+	}
+
+	return nil
+}
+
+// SetImportStatus moves an import job to a new status, stamping finished_at
+// when it completes or fails
+func SetImportStatus(ctx context.Context, importID int, status string) error {
+	_, err := DB.Exec(ctx, `
+		UPDATE imports SET
+			status = $2,
+			finished_at = CASE WHEN $2 IN ('completed', 'failed') THEN now() END
+		WHERE id = $1
+	`, importID, status)
+	if err != nil {
+		return fmt.Errorf("failed to update import status: %w", err)
+	}
+
+	return nil
+}
+
+// UnfinishedImports returns the IDs of import jobs that are pending or were
+// interrupted while running
+func UnfinishedImports(ctx context.Context) ([]int, error) {
+	rows, err := DB.Query(ctx, "SELECT id FROM imports WHERE status IN ('pending', 'running') ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch unfinished imports: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan import id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
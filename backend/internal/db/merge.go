@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MergeResult summarizes a creator merge
+type MergeResult struct {
+	TargetID    int   `json:"target_id"`
+	DuplicateID int   `json:"duplicate_id"`
+	MovedTags   int64 `json:"moved_tags"`  // Tags moved to the target as they were
+	MergedTags  int64 `json:"merged_tags"` // Creator tags folded into another with the same tag
+	MovedVotes  int64 `json:"moved_votes"` // Votes carried over from merged tags
+}
+
+// MergeCreators moves the tags and votes of duplicateID into targetID and
+// soft-deletes the duplicate, in one transaction. When both creators carry
+// the same tag, whoever added it, the creator tag with the highest net score
+// survives and takes over the others' votes from users who had not voted on
+// it yet, so the target keeps one creator tag per tag.
+func MergeCreators(ctx context.Context, targetID, duplicateID int) (MergeResult, error) {
+	result := MergeResult{TargetID: targetID, DuplicateID: duplicateID}
+
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock both creators in ID order so concurrent merges cannot deadlock
+	var locked int
+	err = tx.QueryRow(ctx, `
+		SELECT count(*) FROM (
+			SELECT id FROM creators
+			WHERE id IN ($1, $2) AND deleted_at IS NULL
+			ORDER BY id
+			FOR UPDATE
+		) c
+	`, targetID, duplicateID).Scan(&locked)
+	if err != nil {
+		return result, fmt.Errorf("failed to lock creators: %w", err)
+	}
+	if locked != 2 {
+		return result, fmt.Errorf("merge %d into %d: %w", duplicateID, targetID, ErrNotFound)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT ct.id, ct.creator_id, ct.tag_id,
+			(SELECT COALESCE(SUM(v.vote_type), 0) FROM votes v WHERE v.creator_tag_id = ct.id)
+		FROM creator_tags ct
+		WHERE ct.creator_id IN ($1, $2)
+	`, targetID, duplicateID)
+	if err != nil {
+		return result, fmt.Errorf("failed to list creator tags to merge: %w", err)
+	}
+
+	creators := map[int]int{}
+	var candidates []mergeCandidate
+	for rows.Next() {
+		var c mergeCandidate
+		var creatorID, tagID int
+		if err := rows.Scan(&c.id, &creatorID, &tagID, &c.score); err != nil {
+			rows.Close()
+			return result, fmt.Errorf("failed to scan creator tag: %w", err)
+		}
+		// Everything ends up on the target, so creator tags collide by tag
+		c.group = tagID
+		creators[c.id] = creatorID
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("failed to list creator tags to merge: %w", err)
+	}
+
+	// Surviving duplicate tags move to the target below with the rest
+	survivors := map[int]bool{}
+	for _, m := range planTagMerges(candidates) {
+		moved, err := mergeCreatorTag(ctx, tx, m.from, m.into)
+		if err != nil {
+			return result, err
+		}
+		if creators[m.into] == duplicateID {
+			survivors[m.into] = true
+		}
+		result.MergedTags++
+		result.MovedVotes += moved
+	}
+
+	tag, err := tx.Exec(ctx, "UPDATE creator_tags SET creator_id = $1 WHERE creator_id = $2", targetID, duplicateID)
+	if err != nil {
+		return result, fmt.Errorf("failed to move creator tags: %w", err)
+	}
+	result.MovedTags = tag.RowsAffected() - int64(len(survivors))
+
+	// Earlier merges into the duplicate now point at the target
+	_, err = tx.Exec(ctx, `
+		UPDATE creators SET merged_into_id = $1 WHERE merged_into_id = $2
+	`, targetID, duplicateID)
+	if err != nil {
+		return result, fmt.Errorf("failed to repoint merged creators: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE creators SET deleted_at = now(), merged_into_id = $1 WHERE id = $2
+	`, targetID, duplicateID)
+	if err != nil {
+		return result, fmt.Errorf("failed to mark creator merged: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	return result, nil
+}
+
+// mergeCandidate is a creator tag that may be merged with others in its
+// group, scored by its net votes
+type mergeCandidate struct {
+	id, group, score int
+}
+
+// tagMerge folds creator tag from into creator tag into
+type tagMerge struct {
+	from, into int
+}
+
+// planTagMerges keeps one creator tag per group: the one with the highest
+// net score, ties going to the oldest. The others merge into it best scored
+// first, so a user's vote carried over is the one on the best scored of them.
+func planTagMerges(candidates []mergeCandidate) []tagMerge {
+	sorted := append([]mergeCandidate(nil), candidates...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.group != b.group {
+			return a.group < b.group
+		}
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return a.id < b.id
+	})
+
+	var merges []tagMerge
+	var survivor mergeCandidate
+	for i, c := range sorted {
+		if i == 0 || c.group != survivor.group {
+			survivor = c
+			continue
+		}
+		merges = append(merges, tagMerge{from: c.id, into: survivor.id})
+	}
+	return merges
+}
+
+// mergeCreatorTag folds creator tag fromID into intoID: votes on fromID move
+// over unless the voter already voted on intoID, then fromID is deleted along
+// with its remaining votes and intoID's score refreshed. It returns the
+// number of votes moved.
+func mergeCreatorTag(ctx context.Context, tx pgx.Tx, fromID, intoID int) (int64, error) {
+	if err := lockCreatorTag(ctx, tx, intoID); err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE votes SET creator_tag_id = $2
+		WHERE creator_tag_id = $1
+			AND user_id NOT IN (SELECT user_id FROM votes WHERE creator_tag_id = $2)
+	`, fromID, intoID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move votes: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM creator_tags WHERE id = $1", fromID); err != nil {
+		return 0, fmt.Errorf("failed to delete merged creator tag: %w", err)
+	}
+
+	if err := refreshTagScore(ctx, tx, intoID); err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
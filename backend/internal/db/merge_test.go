@@ -0,0 +1,64 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanTagMerges(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []mergeCandidate
+		want       []tagMerge
+	}{
+		{
+			name:       "tags without a match stay",
+			candidates: []mergeCandidate{{id: 1, group: 10}, {id: 2, group: 11}},
+			want:       nil,
+		},
+		{
+			// The target's creator tag 1 and the duplicate's creator tag 5
+			// carry tag 10 from different users
+			name: "same tag from different users merges into the higher score",
+			candidates: []mergeCandidate{
+				{id: 1, group: 10, score: 2},
+				{id: 2, group: 11, score: 4},
+				{id: 5, group: 10, score: 3},
+				{id: 6, group: 12, score: 0},
+			},
+			want: []tagMerge{{from: 1, into: 5}},
+		},
+		{
+			name:       "ties go to the oldest",
+			candidates: []mergeCandidate{{id: 7, group: 10, score: 1}, {id: 3, group: 10, score: 1}},
+			want:       []tagMerge{{from: 7, into: 3}},
+		},
+		{
+			name: "negative scores lose to unvoted tags",
+			candidates: []mergeCandidate{
+				{id: 1, group: 10, score: -2},
+				{id: 2, group: 10, score: 0},
+			},
+			want: []tagMerge{{from: 1, into: 2}},
+		},
+		{
+			name: "several merge into one, best scored first",
+			candidates: []mergeCandidate{
+				{id: 4, group: 10, score: 1},
+				{id: 2, group: 20, score: 0},
+				{id: 1, group: 10, score: 5},
+				{id: 3, group: 10, score: 2},
+				{id: 5, group: 20, score: 0},
+			},
+			want: []tagMerge{{from: 3, into: 1}, {from: 4, into: 1}, {from: 5, into: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planTagMerges(tt.candidates); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planTagMerges = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
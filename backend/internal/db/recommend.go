@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/recommend"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+)
+
+// Kinds of user activity that shape recommendations
+const (
+	SignalUpvoted  = "upvoted"
+	SignalTagged   = "tagged"
+	SignalFollowed = "followed"
+	SignalCoLiked  = "co_liked" // Users who like a creator the user likes also like this one
+)
+
+// RecommendationReason is the piece of a user's activity that contributes
+// most to a recommendation: the user's Kind of activity with Tag on the
+// creator CreatorID. Tag is empty for SignalCoLiked.
+type RecommendationReason struct {
+	Kind        string `json:"kind"`
+	Tag         string `json:"tag,omitempty"`
+	CreatorID   int    `json:"creator_id"`
+	CreatorName string `json:"creator_name"`
+}
+
+// Recommendation is a creator recommended to a user
+type Recommendation struct {
+	ID          int                   `json:"id"`
+	YouTubeID   string                `json:"youtube_id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Score       float64               `json:"score"`
+	TagScore    float64               `json:"tag_score"`
+	CFScore     float64               `json:"cf_score"`
+	Reason      *RecommendationReason `json:"reason"`
+}
+
+// GetRecommendations ranks creators the user has not interacted with by a
+// blend of two scores, weighted as configured under recommendations:
+//
+//   - the tag score, the cosine similarity of the creator's IDF-weighted tag
+//     vector, from the weights stored for similar creators, to the user's
+//     taste profile. The profile is built from the tags the user upvoted or
+//     submitted and the tags of creators they follow through imported
+//     subscriptions.
+//   - the CF score, the similarity of the creator to those the user upvoted or
+//     tagged, from the neighbors written by cmd/recompute, averaged over the
+//     user's interactions.
+//
+// Creators the user voted on, tagged or follows are left out.
+func GetRecommendations(ctx context.Context, userID, limit int) ([]Recommendation, error) {
+	cfg := config.AppConfig.Recommendations
+
+	rows, err := DB.Query(ctx, `
+		WITH signals AS (
+			SELECT ct.tag_id, ct.creator_id, $2::text AS kind, $5::float8 AS weight, v.updated_at AS at
+			FROM votes v
+			JOIN creator_tags ct ON ct.id = v.creator_tag_id
+			WHERE v.user_id = $1 AND v.vote_type = 1
+			UNION ALL
+			SELECT ct.tag_id, ct.creator_id, $3::text, $6::float8, ct.created_at
+			FROM creator_tags ct
+			WHERE ct.user_id = $1
+			UNION ALL
+			SELECT w.tag_id, w.creator_id, $4::text, $7::float8 * w.weight, i.created_at
+			FROM imports i
+			JOIN import_rows r ON r.import_id = i.id
+			JOIN creator_tag_weights w ON w.creator_id = r.creator_id
+			WHERE i.user_id = $1 AND w.weight > 0
+		),
+		seen AS (
+			SELECT creator_id FROM signals
+			UNION
+			SELECT ct.creator_id
+			FROM votes v
+			JOIN creator_tags ct ON ct.id = v.creator_tag_id
+			WHERE v.user_id = $1
+			UNION
+			SELECT r.creator_id
+			FROM imports i
+			JOIN import_rows r ON r.import_id = i.id
+			WHERE i.user_id = $1 AND r.creator_id IS NOT NULL
+		),
+		profile AS (
+			SELECT s.tag_id, sum(s.weight) * i.idf AS weight, i.idf
+			FROM signals s
+			JOIN tag_idf i ON i.tag_id = s.tag_id
+			GROUP BY s.tag_id, i.idf
+		),
+		profile_norm AS (
+			SELECT sqrt(sum(power(weight, 2))) AS norm FROM profile
+		),
+		-- Reads only the stored weights of the profile's tags, through the
+		-- tag_id index, rather than aggregating every creator's tags
+		tag_scores AS (
+			SELECT w.creator_id, least(sum(p.weight * w.weight * p.idf) / (cv.norm * pn.norm), 1) AS score
+			FROM profile p
+			JOIN creator_tag_weights w ON w.tag_id = p.tag_id AND w.weight > 0
+			JOIN creator_vectors cv ON cv.creator_id = w.creator_id
+			CROSS JOIN profile_norm pn
+			WHERE w.creator_id NOT IN (SELECT creator_id FROM seen)
+			GROUP BY w.creator_id, cv.norm, pn.norm
+		),
+		interactions AS (
+			SELECT creator_id, weight FROM user_creator_interactions WHERE user_id = $1
+		),
+		cf_scores AS (
+			SELECT n.neighbor_id AS creator_id,
+				sum(n.score * ui.weight) / (SELECT sum(weight) FROM interactions) AS score
+			FROM interactions ui
+			JOIN creator_cf_neighbors n ON n.creator_id = ui.creator_id
+			WHERE n.neighbor_id NOT IN (SELECT creator_id FROM seen)
+			GROUP BY n.neighbor_id
+		),
+		ranked AS (
+			SELECT creator_id, COALESCE(t.score, 0) AS tag_score, COALESCE(f.score, 0) AS cf_score,
+				$8::float8 * COALESCE(t.score, 0) + $9::float8 * COALESCE(f.score, 0) AS score
+			FROM tag_scores t
+			FULL JOIN cf_scores f USING (creator_id)
+			JOIN creators c ON c.id = creator_id
+			WHERE c.deleted_at IS NULL
+			ORDER BY score DESC, creator_id
+			LIMIT $10
+		)
+		SELECT c.id, c.youtube_id, c.name, COALESCE(c.description, ''), r.score, r.tag_score, r.cf_score,
+			reason.kind, reason.tag, reason.creator_id, reason.creator_name,
+			co.creator_id, co.creator_name
+		FROM ranked r
+		JOIN creators c ON c.id = r.creator_id
+		LEFT JOIN LATERAL (
+			SELECT s.kind, t.name AS tag, s.creator_id, sc.name AS creator_name
+			FROM signals s
+			JOIN creator_tag_weights w ON w.creator_id = r.creator_id AND w.tag_id = s.tag_id
+			JOIN tag_idf i ON i.tag_id = s.tag_id
+			JOIN tags t ON t.id = s.tag_id
+			JOIN creators sc ON sc.id = s.creator_id AND sc.deleted_at IS NULL
+			ORDER BY s.weight * w.weight * i.idf DESC, s.at DESC, s.creator_id
+			LIMIT 1
+		) reason ON true
+		LEFT JOIN LATERAL (
+			SELECT ui.creator_id, sc.name AS creator_name
+			FROM interactions ui
+			JOIN creator_cf_neighbors n ON n.creator_id = ui.creator_id AND n.neighbor_id = r.creator_id
+			JOIN creators sc ON sc.id = ui.creator_id
+			ORDER BY n.score * ui.weight DESC, ui.creator_id
+			LIMIT 1
+		) co ON true
+		ORDER BY r.score DESC, c.id
+	`, userID, SignalUpvoted, SignalTagged, SignalFollowed,
+		recommend.UpvoteSignalWeight, recommend.TagSignalWeight, recommend.FollowSignalWeight,
+		cfg.TagWeight, cfg.CFWeight, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recommendations: %w", err)
+	}
+	defer rows.Close()
+
+	recommendations := []Recommendation{}
+	for rows.Next() {
+		var rec Recommendation
+		var kind, tag, creatorName, coLikedName *string
+		var creatorID, coLikedID *int
+		if err := rows.Scan(&rec.ID, &rec.YouTubeID, &rec.Name, &rec.Description,
+			&rec.Score, &rec.TagScore, &rec.CFScore,
+			&kind, &tag, &creatorID, &creatorName, &coLikedID, &coLikedName); err != nil {
+			return nil, fmt.Errorf("failed to scan recommendation: %w", err)
+		}
+
+		// Explain by whichever score contributes more
+		switch {
+		case kind != nil && (coLikedID == nil || cfg.TagWeight*rec.TagScore >= cfg.CFWeight*rec.CFScore):
+			rec.Reason = &RecommendationReason{Kind: *kind, Tag: *tag, CreatorID: *creatorID, CreatorName: *creatorName}
+		case coLikedID != nil:
+			rec.Reason = &RecommendationReason{Kind: SignalCoLiked, CreatorID: *coLikedID, CreatorName: *coLikedName}
+		}
+		recommendations = append(recommendations, rec)
+	}
+
+	return recommendations, rows.Err()
+}
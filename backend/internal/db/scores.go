@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/scoring"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier runs queries on the pool or inside a transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// lockCreatorTag serializes vote changes on a creator tag so they are
+// tallied one after the other. Callers take it before writing votes. FOR NO
+// KEY UPDATE does not conflict with the KEY SHARE lock that the votes
+// foreign key takes, so two first votes on a creator tag cannot deadlock.
+func lockCreatorTag(ctx context.Context, q querier, creatorTagID int) error {
+	if _, err := q.Exec(ctx, "SELECT 1 FROM creator_tags WHERE id = $1 FOR NO KEY UPDATE", creatorTagID); err != nil {
+		return fmt.Errorf("failed to lock creator tag: %w", err)
+	}
+	return nil
+}
+
+// refreshTagScore recomputes the creator_tag_scores row of a creator tag
+// from its votes, locking the creator tag if the caller has not yet
+func refreshTagScore(ctx context.Context, q querier, creatorTagID int) error {
+	if err := lockCreatorTag(ctx, q, creatorTagID); err != nil {
+		return err
+	}
+
+	rows, err := q.Query(ctx, "SELECT vote_type, updated_at FROM votes WHERE creator_tag_id = $1", creatorTagID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch votes: %w", err)
+	}
+
+	var votes []scoring.Vote
+	for rows.Next() {
+		var v scoring.Vote
+		if err := rows.Scan(&v.Type, &v.At); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan vote row: %w", err)
+		}
+		votes = append(votes, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to fetch votes: %w", err)
+	}
+
+	tally := scoring.Count(votes, time.Now(), config.AppConfig.Scoring.HalfLife)
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO creator_tag_scores
+			(creator_tag_id, upvotes, downvotes, wilson, decayed_up, decayed_down, decayed_at, updated_at)
+		SELECT id, $2, $3, $4, $5, $6, $7, now() FROM creator_tags WHERE id = $1
+		ON CONFLICT (creator_tag_id) DO UPDATE SET
+			upvotes = EXCLUDED.upvotes,
+			downvotes = EXCLUDED.downvotes,
+			wilson = EXCLUDED.wilson,
+			decayed_up = EXCLUDED.decayed_up,
+			decayed_down = EXCLUDED.decayed_down,
+			decayed_at = EXCLUDED.decayed_at,
+			updated_at = now()
+	`, creatorTagID, tally.Up, tally.Down, tally.Wilson(), tally.DecayedUp, tally.DecayedDown, tally.DecayedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store tag score: %w", err)
+	}
+
+	return nil
+}
+
+// BackfillTagScores computes scores for creator tags that have none yet,
+// such as those that existed before scores were stored
+func BackfillTagScores(ctx context.Context) error {
+	rows, err := DB.Query(ctx, `
+		SELECT ct.id FROM creator_tags ct
+		WHERE NOT EXISTS (SELECT 1 FROM creator_tag_scores s WHERE s.creator_tag_id = ct.id)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to list unscored creator tags: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan creator tag row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list unscored creator tags: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := refreshTagScore(ctx, DB, id); err != nil {
+			return err
+		}
+	}
+
+	if len(ids) > 0 {
+		logger.Log.Info("Backfilled tag scores", "creator_tags", len(ids))
+	}
+	return nil
+}
+
+// tagScoreSQL returns an SQL expression for the relevance of the
+// creator_tag_scores row aliased s: its Wilson score, or with
+// scoring.time_decay set, the Wilson score of its decayed sums aged to asOf.
+// Creator tags without a row score 0. Parameters are appended to args.
+func tagScoreSQL(s string, asOf time.Time, args *[]interface{}) string {
+	cfg := config.AppConfig.Scoring
+	if !cfg.TimeDecay || cfg.HalfLife <= 0 {
+		return fmt.Sprintf("COALESCE(%s.wilson, 0)", s)
+	}
+
+	*args = append(*args, asOf, cfg.HalfLife.Seconds())
+	age := fmt.Sprintf("power(0.5, extract(epoch FROM $%d::timestamptz - %s.decayed_at) / $%d)", len(*args)-1, s, len(*args))
+	return fmt.Sprintf("COALESCE(wilson_lower_bound(%[1]s.decayed_up * %[2]s, %[1]s.decayed_down * %[2]s), 0)", s, age)
+}
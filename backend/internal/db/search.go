@@ -0,0 +1,342 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/tagquery"
+)
+
+// Search modes, recorded in cursors so a cursor is only used with its own mode
+const (
+	SearchByTag   = "tag"
+	SearchByQuery = "q"
+	SearchByText  = "text"
+)
+
+// Weights blending full-text relevance with tag relevance in SearchCreatorsByText
+const (
+	textRankWeight = 1.0
+	textTagWeight  = 0.5
+)
+
+// headlineOptions configures the ts_headline snippets returned with text results
+const headlineOptions = "MaxFragments=2, MaxWords=20, MinWords=5, StartSel=<mark>, StopSel=</mark>"
+
+// escapeHTMLSQL wraps an SQL text expression so that it is HTML-escaped.
+// Names and descriptions come from users and YouTube, and ts_headline copies
+// its input verbatim around the <mark> tags it adds, so it is given escaped
+// text to keep the snippets safe to render as HTML.
+func escapeHTMLSQL(expr string) string {
+	return fmt.Sprintf(`replace(replace(replace(replace(replace(%s,
+		'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`, expr)
+}
+
+// SearchCursor marks a position in search results: the ordering key of the
+// last result, and the time its scores were computed at. With time decay on,
+// later pages age scores to that time, so the passing of time alone does not
+// reorder results. Votes cast between page requests still change scores,
+// which are recomputed for every page, so a creator whose score crosses the
+// cursor's can appear on two pages or on none.
+type SearchCursor struct {
+	Mode  string    `json:"m"`
+	Depth int       `json:"d"`
+	Score float64   `json:"s"`
+	Name  string    `json:"n"`
+	ID    int       `json:"i"`
+	AsOf  time.Time `json:"t"`
+}
+
+// SearchOptions controls paging of search results
+type SearchOptions struct {
+	Limit int
+	After *SearchCursor
+}
+
+// SearchResult is a creator matching a search, with the names of its tags
+// that matched
+type SearchResult struct {
+	ID          int      `json:"id"`
+	YouTubeID   string   `json:"youtube_id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Score       float64  `json:"score"`
+	MatchedTags []string `json:"matched_tags"`
+
+	// Set by tag searches
+	DirectMatch *bool `json:"direct_match,omitempty"`
+	MatchDepth  *int  `json:"match_depth,omitempty"`
+
+	// Set by text searches
+	TextRank            *float64 `json:"text_rank,omitempty"`
+	TagScore            *float64 `json:"tag_score,omitempty"`
+	NameHeadline        *string  `json:"name_headline,omitempty"`        // HTML-escaped, matches in <mark>
+	DescriptionHeadline *string  `json:"description_headline,omitempty"` // HTML-escaped, matches in <mark>
+}
+
+// SearchPage is one page of search results. Total counts every match, not
+// just those on the page, even when a cursor past the last match leaves the
+// page empty. Next is nil on the last page.
+type SearchPage struct {
+	Creators []SearchResult
+	Total    int
+	Next     *SearchCursor
+}
+
+// search describes a search for runSearch. matches is a query returning one
+// row per matching creator with the columns creator_id, depth, score,
+// matched_tags, text_rank and tag_score; headline, when set, is the tsquery
+// expression to highlight names and descriptions with.
+type search struct {
+	mode     string
+	matches  string
+	headline string
+}
+
+// SearchCreatorsByTag finds creators with the tag whose slug or alias is
+// given, ranked by the vote score of the tag on each creator. With
+// includeDescendants, creators tagged with any tag below it in the hierarchy
+// match too, ranked after direct matches by distance.
+func SearchCreatorsByTag(ctx context.Context, slug string, includeDescendants bool, opts SearchOptions) (SearchPage, error) {
+	asOf := searchTime(opts)
+	args := []interface{}{slug, includeDescendants, MaxTagDepth}
+	score := tagScoreSQL("s", asOf, &args)
+
+	return runSearch(ctx, search{
+		mode: SearchByTag,
+		matches: `
+			WITH RECURSIVE matched AS (
+				SELECT t.id, 0 AS depth FROM tags t WHERE ` + tagMatchesSlug + `
+				UNION ALL
+				SELECT tp.tag_id, m.depth + 1
+				FROM tag_parents tp
+				JOIN matched m ON tp.parent_id = m.id
+				WHERE $2 AND m.depth < $3
+			)
+			SELECT ct.creator_id, min(m.depth) AS depth, max(` + score + `)::float8 AS score,
+				array_agg(DISTINCT t.name ORDER BY t.name) AS matched_tags,
+				NULL::float8 AS text_rank, NULL::float8 AS tag_score
+			FROM creator_tags ct
+			JOIN matched m ON ct.tag_id = m.id
+			JOIN tags t ON t.id = ct.tag_id
+			JOIN creators c ON c.id = ct.creator_id
+			LEFT JOIN creator_tag_scores s ON s.creator_tag_id = ct.id
+			WHERE c.deleted_at IS NULL
+			GROUP BY ct.creator_id
+		`,
+	}, args, asOf, opts)
+}
+
+// SearchCreatorsByQuery finds creators matching a boolean tag query, ranked
+// by the summed scores of the query's non-negated tags on each creator
+func SearchCreatorsByQuery(ctx context.Context, query tagquery.Node, opts SearchOptions) (SearchPage, error) {
+	asOf := searchTime(opts)
+	var args []interface{}
+	where := compileQuery(query, asOf, &args)
+
+	var slugs []string
+	for _, tag := range tagquery.PositiveTags(query) {
+		slugs = append(slugs, tag.Slug)
+	}
+	args = append(args, slugs)
+	slugsParam := len(args)
+	score := tagScoreSQL("s", asOf, &args)
+
+	return runSearch(ctx, search{
+		mode: SearchByQuery,
+		matches: fmt.Sprintf(`
+			SELECT c.id AS creator_id, 0 AS depth, COALESCE(p.score, 0)::float8 AS score,
+				COALESCE(p.tags, '{}') AS matched_tags,
+				NULL::float8 AS text_rank, NULL::float8 AS tag_score
+			FROM creators c
+			LEFT JOIN LATERAL (
+				SELECT sum(%s) AS score, array_agg(DISTINCT t.name ORDER BY t.name) AS tags
+				FROM creator_tags ct
+				JOIN tags t ON t.id = ct.tag_id
+				LEFT JOIN creator_tag_scores s ON s.creator_tag_id = ct.id
+				WHERE ct.creator_id = c.id AND (t.slug = ANY($%[2]d)
+					OR t.id IN (SELECT tag_id FROM tag_aliases WHERE slug = ANY($%[2]d)))
+			) p ON true
+			WHERE c.deleted_at IS NULL AND %[3]s
+		`, score, slugsParam, where),
+	}, args, asOf, opts)
+}
+
+// compileQuery turns a query into a condition on creators c. Tags become
+// parameters appended to args; no query text reaches the SQL.
+func compileQuery(node tagquery.Node, asOf time.Time, args *[]interface{}) string {
+	switch n := node.(type) {
+	case tagquery.And:
+		return fmt.Sprintf("(%s AND %s)", compileQuery(n.Left, asOf, args), compileQuery(n.Right, asOf, args))
+	case tagquery.Or:
+		return fmt.Sprintf("(%s OR %s)", compileQuery(n.Left, asOf, args), compileQuery(n.Right, asOf, args))
+	case tagquery.Not:
+		return fmt.Sprintf("(NOT %s)", compileQuery(n.Expr, asOf, args))
+	case tagquery.Tag:
+		*args = append(*args, n.Slug)
+		slug := len(*args)
+
+		threshold := ""
+		if n.MinScore != nil {
+			score := tagScoreSQL("s", asOf, args)
+			*args = append(*args, *n.MinScore)
+			threshold = fmt.Sprintf("AND %s >= $%d", score, len(*args))
+		}
+
+		return fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM creator_tags ct
+			JOIN tags t ON t.id = ct.tag_id
+			LEFT JOIN creator_tag_scores s ON s.creator_tag_id = ct.id
+			WHERE ct.creator_id = c.id
+				AND (t.slug = $%[1]d OR t.id = (SELECT tag_id FROM tag_aliases WHERE slug = $%[1]d))
+				%[2]s
+		)`, slug, threshold)
+	default:
+		panic(fmt.Sprintf("tagquery: unknown node %T", node))
+	}
+}
+
+// SearchCreatorsByText finds creators whose name, keywords or description
+// match text, or that carry a tag matching it, ranked by text rank blended
+// with the scores of the matching tags. slug is the normalized form of text,
+// compared against tag slugs and aliases; tag names are also matched as text.
+// Candidates are the union of both kinds of match so that each can use its
+// own index.
+func SearchCreatorsByText(ctx context.Context, text, slug string, opts SearchOptions) (SearchPage, error) {
+	asOf := searchTime(opts)
+	args := []interface{}{text, slug, textRankWeight, textTagWeight}
+	score := tagScoreSQL("s", asOf, &args)
+
+	return runSearch(ctx, search{
+		mode:     SearchByText,
+		headline: "websearch_to_tsquery('english', $1)",
+		matches: `
+			WITH matched_tags AS (
+				SELECT t.id FROM tags t
+				WHERE t.slug = $2
+					OR t.id IN (SELECT tag_id FROM tag_aliases WHERE slug = $2)
+					OR to_tsvector('english', t.name) @@ websearch_to_tsquery('english', $1)
+			),
+			candidates AS (
+				SELECT c.id FROM creators c
+				WHERE c.search_vector @@ websearch_to_tsquery('english', $1)
+				UNION
+				SELECT ct.creator_id FROM creator_tags ct
+				WHERE ct.tag_id IN (SELECT id FROM matched_tags)
+			)
+			SELECT c.id AS creator_id, 0 AS depth,
+				($3::float8 * ts_rank_cd(c.search_vector, q.query, 32) + $4::float8 * COALESCE(tm.score, 0))::float8 AS score,
+				COALESCE(tm.tags, '{}') AS matched_tags,
+				ts_rank_cd(c.search_vector, q.query, 32)::float8 AS text_rank,
+				COALESCE(tm.score, 0)::float8 AS tag_score
+			FROM candidates cand
+			JOIN creators c ON c.id = cand.id
+			CROSS JOIN (SELECT websearch_to_tsquery('english', $1) AS query) q
+			LEFT JOIN LATERAL (
+				SELECT sum(` + score + `) AS score, array_agg(DISTINCT t.name ORDER BY t.name) AS tags
+				FROM creator_tags ct
+				JOIN tags t ON t.id = ct.tag_id
+				LEFT JOIN creator_tag_scores s ON s.creator_tag_id = ct.id
+				WHERE ct.creator_id = c.id AND ct.tag_id IN (SELECT id FROM matched_tags)
+			) tm ON true
+			WHERE c.deleted_at IS NULL
+		`,
+	}, args, asOf, opts)
+}
+
+// searchTime is the time decayed tag scores are aged to: now for a first
+// page, and the first page's time for the pages after it
+func searchTime(opts SearchOptions) time.Time {
+	if opts.After != nil {
+		return opts.After.AsOf
+	}
+	return time.Now()
+}
+
+// runSearch pages through the matches of s, ordered by depth, then score,
+// then name, with the creator ID as the final tie-breaker. Keyset paging over
+// live scores is not a snapshot; see SearchCursor.
+func runSearch(ctx context.Context, s search, args []interface{}, asOf time.Time, opts SearchOptions) (SearchPage, error) {
+	matchArgs := args
+
+	keyset := "true"
+	if opts.After != nil {
+		args = append(args, opts.After.Depth, opts.After.Score, opts.After.Name, opts.After.ID)
+		n := len(args)
+		keyset = fmt.Sprintf("(r.depth, -r.score, c.name, c.id) > ($%d::int, -$%d::float8, $%d::text, $%d::int)", n-3, n-2, n-1, n)
+	}
+
+	headlines := "NULL::text, NULL::text"
+	if s.headline != "" {
+		args = append(args, headlineOptions)
+		headlines = fmt.Sprintf(`ts_headline('english', %[3]s, %[1]s, $%[2]d),
+			ts_headline('english', %[4]s, %[1]s, $%[2]d)`,
+			s.headline, len(args), escapeHTMLSQL("c.name"), escapeHTMLSQL("COALESCE(c.description, '')"))
+	}
+
+	// Fetch one extra row to know whether there is a next page
+	args = append(args, opts.Limit+1)
+	rows, err := DB.Query(ctx, fmt.Sprintf(`
+		WITH results AS (%s)
+		SELECT c.id, c.youtube_id, c.name, COALESCE(c.description, ''),
+			r.depth, r.score, r.matched_tags, r.text_rank, r.tag_score, %s,
+			(SELECT count(*) FROM results)
+		FROM results r
+		JOIN creators c ON c.id = r.creator_id
+		WHERE %s
+		ORDER BY r.depth, r.score DESC, c.name, c.id
+		LIMIT $%d
+	`, s.matches, headlines, keyset, len(args)), args...)
+	if err != nil {
+		return SearchPage{}, fmt.Errorf("failed to search creators: %w", err)
+	}
+	defer rows.Close()
+
+	page := SearchPage{Creators: []SearchResult{}}
+	var depths []int
+	for rows.Next() {
+		var result SearchResult
+		var depth int
+		if err := rows.Scan(&result.ID, &result.YouTubeID, &result.Name, &result.Description,
+			&depth, &result.Score, &result.MatchedTags, &result.TextRank, &result.TagScore,
+			&result.NameHeadline, &result.DescriptionHeadline, &page.Total); err != nil {
+			return SearchPage{}, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		if s.mode == SearchByTag {
+			direct := depth == 0
+			result.DirectMatch, result.MatchDepth = &direct, &depth
+		}
+		page.Creators = append(page.Creators, result)
+		depths = append(depths, depth)
+	}
+	if err := rows.Err(); err != nil {
+		return SearchPage{}, fmt.Errorf("failed to read search results: %w", err)
+	}
+
+	// Rows carry the total, so a page past the last match needs it counted
+	if len(page.Creators) == 0 && opts.After != nil {
+		err := DB.QueryRow(ctx, fmt.Sprintf(`
+			WITH results AS (%s)
+			SELECT count(*) FROM results
+		`, s.matches), matchArgs...).Scan(&page.Total)
+		if err != nil {
+			return SearchPage{}, fmt.Errorf("failed to count search results: %w", err)
+		}
+	}
+
+	if len(page.Creators) > opts.Limit {
+		page.Creators = page.Creators[:opts.Limit]
+		last := page.Creators[opts.Limit-1]
+		page.Next = &SearchCursor{
+			Mode:  s.mode,
+			Depth: depths[opts.Limit-1],
+			Score: last.Score,
+			Name:  last.Name,
+			ID:    last.ID,
+			AsOf:  asOf,
+		}
+	}
+
+	return page, nil
+}
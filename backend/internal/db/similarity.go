@@ -0,0 +1,371 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SimilarityCandidates caps how many creators sharing tags with a rebuilt
+// creator are scored, and so how many neighbor lists the rebuild updates
+const SimilarityCandidates = 1000
+
+// QueuedCreator is a creator waiting for its neighbors to be recomputed
+type QueuedCreator struct {
+	ID       int
+	QueuedAt time.Time
+}
+
+// SimilarCreator is a neighbor of a creator with the tags they share, most
+// telling first
+type SimilarCreator struct {
+	ID         int      `json:"id"`
+	YouTubeID  string   `json:"youtube_id"`
+	Name       string   `json:"name"`
+	Score      float64  `json:"score"`
+	SharedTags []string `json:"shared_tags"`
+}
+
+// idfSQL is the inverse document frequency of a tag on df of n creators.
+// The added one keeps tags on every creator from dropping out entirely.
+const idfSQL = "ln(1 + %[1]s::float8 / greatest(%[2]s, 1))"
+
+// QueueAllSimilarities recomputes the IDF of every tag and queues every
+// live creator, so the next passes rebuild the whole neighbor table
+func QueueAllSimilarities(ctx context.Context) error {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM tag_idf`); err != nil {
+		return fmt.Errorf("failed to clear tag IDF: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		WITH n AS (SELECT count(*) AS creators FROM creators WHERE deleted_at IS NULL)
+		INSERT INTO tag_idf (tag_id, creators, idf)
+		SELECT w.tag_id, count(*), `+fmt.Sprintf(idfSQL, "n.creators", "count(*)")+`
+		FROM creator_tag_weights w, n
+		GROUP BY w.tag_id, n.creators
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to compute tag IDF: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO similarity_queue (creator_id)
+		SELECT id FROM creators WHERE deleted_at IS NULL
+		ON CONFLICT (creator_id) DO UPDATE SET queued_at = now()
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to queue creators: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit similarity queue: %w", err)
+	}
+	return nil
+}
+
+// SimilarityBuilt reports whether tag IDF has ever been computed, that is
+// whether QueueAllSimilarities has run
+func SimilarityBuilt(ctx context.Context) (bool, error) {
+	var built bool
+	if err := DB.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM tag_idf)`).Scan(&built); err != nil {
+		return false, fmt.Errorf("failed to check tag IDF: %w", err)
+	}
+	return built, nil
+}
+
+// NextQueuedCreators returns up to limit queued creators, oldest first
+func NextQueuedCreators(ctx context.Context, limit int) ([]QueuedCreator, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT creator_id, queued_at FROM similarity_queue
+		ORDER BY queued_at, creator_id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list similarity queue: %w", err)
+	}
+	defer rows.Close()
+
+	var queued []QueuedCreator
+	for rows.Next() {
+		var q QueuedCreator
+		if err := rows.Scan(&q.ID, &q.QueuedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan similarity queue row: %w", err)
+		}
+		queued = append(queued, q)
+	}
+
+	return queued, rows.Err()
+}
+
+// RefreshTagIDF recomputes the IDF of the tags on the given creators, so
+// rebuilding them uses current tag frequencies. IDF of other tags is only
+// refreshed by QueueAllSimilarities.
+func RefreshTagIDF(ctx context.Context, creatorIDs []int) error {
+	_, err := DB.Exec(ctx, `
+		WITH n AS (SELECT count(*) AS creators FROM creators WHERE deleted_at IS NULL),
+		changed AS (SELECT DISTINCT tag_id FROM creator_tags WHERE creator_id = ANY($1))
+		INSERT INTO tag_idf (tag_id, creators, idf)
+		SELECT w.tag_id, count(*), `+fmt.Sprintf(idfSQL, "n.creators", "count(*)")+`
+		FROM creator_tag_weights w
+		JOIN changed ON changed.tag_id = w.tag_id, n
+		GROUP BY w.tag_id, n.creators
+		ON CONFLICT (tag_id) DO UPDATE SET
+			creators = EXCLUDED.creators,
+			idf = EXCLUDED.idf,
+			updated_at = now()
+	`, creatorIDs)
+	if err != nil {
+		return fmt.Errorf("failed to refresh tag IDF: %w", err)
+	}
+	return nil
+}
+
+// RebuildNeighbors recomputes the neighbors of a queued creator: the k
+// creators with the highest cosine similarity between IDF-weighted tag
+// vectors. The creator also replaces its entries in the neighbor lists of
+// the creators it was scored against, so both directions stay current
+// without rebuilding them. The queue entry is removed unless the creator was
+// queued again meanwhile.
+func RebuildNeighbors(ctx context.Context, creator QueuedCreator, k int) error {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var norm *float64
+	err = tx.QueryRow(ctx, `
+		SELECT sqrt(sum(power(w.weight * i.idf, 2)))
+		FROM creator_tag_weights w
+		JOIN tag_idf i ON i.tag_id = w.tag_id
+		WHERE w.creator_id = $1 AND w.weight > 0
+	`, creator.ID).Scan(&norm)
+	if err != nil {
+		return fmt.Errorf("failed to compute creator vector: %w", err)
+	}
+
+	// Creators without weighted tags, deleted ones included, have no neighbors
+	if norm == nil || *norm == 0 {
+		if _, err := tx.Exec(ctx, `DELETE FROM creator_vectors WHERE creator_id = $1`, creator.ID); err != nil {
+			return fmt.Errorf("failed to clear creator vector: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			DELETE FROM creator_neighbors WHERE creator_id = $1 OR neighbor_id = $1
+		`, creator.ID)
+		if err != nil {
+			return fmt.Errorf("failed to clear creator neighbors: %w", err)
+		}
+		if err := dequeueSimilarity(ctx, tx, creator); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit creator neighbors: %w", err)
+		}
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO creator_vectors (creator_id, norm)
+		VALUES ($1, $2)
+		ON CONFLICT (creator_id) DO UPDATE SET norm = EXCLUDED.norm, computed_at = now()
+	`, creator.ID, *norm)
+	if err != nil {
+		return fmt.Errorf("failed to store creator vector: %w", err)
+	}
+
+	// Score creators sharing a tag, using the vector norms stored when they
+	// were last rebuilt; creators not yet built join once they are. Stale
+	// norms can push a score slightly past 1.
+	rows, err := tx.Query(ctx, `
+		WITH x AS (
+			SELECT w.tag_id, w.weight * i.idf AS v
+			FROM creator_tag_weights w
+			JOIN tag_idf i ON i.tag_id = w.tag_id
+			WHERE w.creator_id = $1 AND w.weight > 0
+		)
+		SELECT y.creator_id, least(sum(x.v * y.weight * i.idf) / ($2 * v.norm), 1) AS score
+		FROM x
+		JOIN tag_idf i ON i.tag_id = x.tag_id
+		JOIN creator_tag_weights y ON y.tag_id = x.tag_id AND y.creator_id <> $1 AND y.weight > 0
+		JOIN creator_vectors v ON v.creator_id = y.creator_id
+		GROUP BY y.creator_id, v.norm
+		ORDER BY score DESC, y.creator_id
+		LIMIT $3
+	`, creator.ID, *norm, SimilarityCandidates)
+	if err != nil {
+		return fmt.Errorf("failed to score similar creators: %w", err)
+	}
+
+	ids, scores := []int{}, []float64{}
+	for rows.Next() {
+		var id int
+		var score float64
+		if err := rows.Scan(&id, &score); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan similar creator: %w", err)
+		}
+		ids = append(ids, id)
+		scores = append(scores, score)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read similar creators: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM creator_neighbors WHERE creator_id = $1`, creator.ID); err != nil {
+		return fmt.Errorf("failed to clear creator neighbors: %w", err)
+	}
+
+	top := min(k, len(ids))
+	_, err = tx.Exec(ctx, `
+		INSERT INTO creator_neighbors (creator_id, neighbor_id, score)
+		SELECT $1, unnest($2::int[]), unnest($3::float8[])
+	`, creator.ID, ids[:top], scores[:top])
+	if err != nil {
+		return fmt.Errorf("failed to store creator neighbors: %w", err)
+	}
+
+	// Update the other direction: drop links from creators no longer scored,
+	// set the new scores, and trim each touched list back to k
+	_, err = tx.Exec(ctx, `
+		DELETE FROM creator_neighbors WHERE neighbor_id = $1 AND creator_id <> ALL($2)
+	`, creator.ID, ids)
+	if err != nil {
+		return fmt.Errorf("failed to clear reverse neighbors: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO creator_neighbors (creator_id, neighbor_id, score)
+		SELECT unnest($2::int[]), $1, unnest($3::float8[])
+		ON CONFLICT (creator_id, neighbor_id) DO UPDATE SET
+			score = EXCLUDED.score,
+			computed_at = now()
+	`, creator.ID, ids, scores)
+	if err != nil {
+		return fmt.Errorf("failed to store reverse neighbors: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM creator_neighbors n
+		USING (
+			SELECT creator_id, neighbor_id,
+				row_number() OVER (PARTITION BY creator_id ORDER BY score DESC, neighbor_id) AS rank
+			FROM creator_neighbors
+			WHERE creator_id = ANY($1)
+		) r
+		WHERE n.creator_id = r.creator_id AND n.neighbor_id = r.neighbor_id AND r.rank > $2
+	`, ids, k)
+	if err != nil {
+		return fmt.Errorf("failed to trim reverse neighbors: %w", err)
+	}
+
+	if err := dequeueSimilarity(ctx, tx, creator); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit creator neighbors: %w", err)
+	}
+	return nil
+}
+
+// dequeueSimilarity removes a creator from the queue unless it was queued
+// again after being picked up
+func dequeueSimilarity(ctx context.Context, q querier, creator QueuedCreator) error {
+	_, err := q.Exec(ctx, `
+		DELETE FROM similarity_queue WHERE creator_id = $1 AND queued_at <= $2
+	`, creator.ID, creator.QueuedAt)
+	if err != nil {
+		return fmt.Errorf("failed to dequeue creator: %w", err)
+	}
+	return nil
+}
+
+// RequeueSimilarity moves a creator to the back of the queue, so one that
+// fails to rebuild doesn't hold up those behind it
+func RequeueSimilarity(ctx context.Context, creatorID int) error {
+	_, err := DB.Exec(ctx, `
+		UPDATE similarity_queue SET queued_at = now() WHERE creator_id = $1
+	`, creatorID)
+	if err != nil {
+		return fmt.Errorf("failed to requeue creator: %w", err)
+	}
+	return nil
+}
+
+// GetSimilarCreators returns up to limit live neighbors of a creator, most
+// similar first, with the tags they share ordered by how much each
+// contributes to the similarity
+func GetSimilarCreators(ctx context.Context, creatorID, limit int) ([]SimilarCreator, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT c.id, c.youtube_id, c.name, n.score,
+			ARRAY(
+				SELECT t.name
+				FROM creator_tag_weights a
+				JOIN creator_tag_weights b ON b.tag_id = a.tag_id AND b.creator_id = n.neighbor_id
+				JOIN tags t ON t.id = a.tag_id
+				LEFT JOIN tag_idf i ON i.tag_id = a.tag_id
+				WHERE a.creator_id = n.creator_id AND a.weight > 0 AND b.weight > 0
+				ORDER BY a.weight * b.weight * COALESCE(i.idf, 0) DESC, t.name
+			)
+		FROM creator_neighbors n
+		JOIN creators c ON c.id = n.neighbor_id
+		WHERE n.creator_id = $1 AND c.deleted_at IS NULL
+		ORDER BY n.score DESC, n.neighbor_id
+		LIMIT $2
+	`, creatorID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch similar creators: %w", err)
+	}
+	defer rows.Close()
+
+	similar := []SimilarCreator{}
+	for rows.Next() {
+		var s SimilarCreator
+		if err := rows.Scan(&s.ID, &s.YouTubeID, &s.Name, &s.Score, &s.SharedTags); err != nil {
+			return nil, fmt.Errorf("failed to scan similar creator: %w", err)
+		}
+		similar = append(similar, s)
+	}
+
+	return similar, rows.Err()
+}
+
+// GetTagVectors returns the IDF-weighted tag vectors of the given creators,
+// keyed by creator ID and then tag ID. Tags without IDF yet weigh as if they
+// were on a single creator.
+func GetTagVectors(ctx context.Context, creatorIDs []int) (map[int]map[int]float64, error) {
+	rows, err := DB.Query(ctx, `
+		WITH n AS (SELECT count(*) AS creators FROM creators WHERE deleted_at IS NULL)
+		SELECT w.creator_id, w.tag_id, w.weight * COALESCE(i.idf, `+fmt.Sprintf(idfSQL, "n.creators", "1")+`)
+		FROM creator_tag_weights w
+		LEFT JOIN tag_idf i ON i.tag_id = w.tag_id
+		CROSS JOIN n
+		WHERE w.creator_id = ANY($1) AND w.weight > 0
+	`, creatorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tag vectors: %w", err)
+	}
+	defer rows.Close()
+
+	vectors := map[int]map[int]float64{}
+	for rows.Next() {
+		var creatorID, tagID int
+		var weight float64
+		if err := rows.Scan(&creatorID, &tagID, &weight); err != nil {
+			return nil, fmt.Errorf("failed to scan tag vector: %w", err)
+		}
+		if vectors[creatorID] == nil {
+			vectors[creatorID] = map[int]float64{}
+		}
+		vectors[creatorID][tagID] = weight
+	}
+
+	return vectors, rows.Err()
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StatsSnapshot is a channel's public counters at a point in time
+type StatsSnapshot struct {
+	CapturedAt      time.Time `json:"captured_at"`
+	SubscriberCount *int64    `json:"subscriber_count"` // nil when the channel hides it
+	ViewCount       int64     `json:"view_count"`
+	VideoCount      int64     `json:"video_count"`
+}
+
+// RecordCreatorStats appends a statistics snapshot to a creator's history and
+// keeps the creator's current subscriber count, used for sorting, up to date
+func RecordCreatorStats(ctx context.Context, creatorID int, stats StatsSnapshot) error {
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		INSERT INTO creator_stats (creator_id, subscriber_count, view_count, video_count)
+		VALUES ($1, $2, $3, $4)
+	`, creatorID, stats.SubscriberCount, stats.ViewCount, stats.VideoCount)
+	batch.Queue("UPDATE creators SET subscriber_count = $2 WHERE id = $1", creatorID, stats.SubscriberCount)
+
+	if err := DB.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to record creator stats: %w", err)
+	}
+
+	return nil
+}
+
+// GetCreatorStats returns a creator's statistics between from and to,
+// downsampled to the last snapshot in each interval bucket ("day", "week" or "month")
+func GetCreatorStats(ctx context.Context, creatorID int, from, to time.Time, interval string) ([]StatsSnapshot, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT DISTINCT ON (date_trunc($4, captured_at))
+			captured_at, subscriber_count, view_count, video_count
+		FROM creator_stats
+		WHERE creator_id = $1 AND captured_at >= $2 AND captured_at < $3
+		ORDER BY date_trunc($4, captured_at), captured_at DESC
+	`, creatorID, from, to, interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch creator stats: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []StatsSnapshot{}
+	for rows.Next() {
+		var s StatsSnapshot
+		if err := rows.Scan(&s.CapturedAt, &s.SubscriberCount, &s.ViewCount, &s.VideoCount); err != nil {
+			return nil, fmt.Errorf("failed to scan stats row: %w", err)
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,426 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/tagnorm"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+)
+
+// tagMatchesSlug matches tag t against the slug in $1, directly or through an alias
+const tagMatchesSlug = `(t.slug = $1 OR t.id = (SELECT tag_id FROM tag_aliases WHERE slug = $1))`
+
+// findOrCreateTag returns the canonical tag for slug, resolving aliases and
+// creating the tag with name when nothing matches
+func findOrCreateTag(ctx context.Context, name, slug string) (int, string, error) {
+	var tagID int
+	var tagName string
+
+	err := DB.QueryRow(ctx, `
+		SELECT t.id, t.name FROM tag_aliases a
+		JOIN tags t ON t.id = a.tag_id
+		WHERE a.slug = $1
+	`, slug).Scan(&tagID, &tagName)
+	if err == nil {
+		return tagID, tagName, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", fmt.Errorf("failed to resolve tag alias: %w", err)
+	}
+
+	// Create the tag unless a tag with the same slug exists, then look it up
+	_, err = DB.Exec(ctx, "INSERT INTO tags (name, slug) VALUES ($1, $2) ON CONFLICT DO NOTHING", name, slug)
+	if err != nil {
+		return 0, "", wrapErr("failed to insert tag", err)
+	}
+
+	err = DB.QueryRow(ctx, `
+		SELECT id, name FROM tags WHERE slug = $2 OR name = $1
+		ORDER BY slug = $2 DESC
+		LIMIT 1
+	`, name, slug).Scan(&tagID, &tagName)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to check tag: %w", err)
+	}
+
+	return tagID, tagName, nil
+}
+
+// BackfillTagSlugs gives tags without a slug, such as those that existed
+// before slugs were stored, their tagnorm slug and normalized name. Tags
+// that share a slug, with each other or with a tag that already has it, are
+// consolidated into the oldest of them. Slugs are computed here rather than
+// in SQL since lower() and [:alnum:] depend on the database's locale.
+func BackfillTagSlugs(ctx context.Context) error {
+	rows, err := DB.Query(ctx, "SELECT id, name FROM tags WHERE slug IS NULL ORDER BY id")
+	if err != nil {
+		return fmt.Errorf("failed to list tags without slugs: %w", err)
+	}
+
+	type unslugged struct {
+		id   int
+		name string
+	}
+	groups := map[string][]unslugged{}
+	var slugs []string
+	for rows.Next() {
+		var t unslugged
+		if err := rows.Scan(&t.id, &t.name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan tag row: %w", err)
+		}
+
+		slug := tagnorm.Slug(t.name)
+		if normalized, err := tagnorm.Normalize(t.name); err == nil {
+			t.name = normalized.Name
+		}
+		// Tags with nothing left keep a placeholder slug rather than losing their votes
+		if slug == "" {
+			slug = fmt.Sprintf("tag-%d", t.id)
+		}
+
+		if groups[slug] == nil {
+			slugs = append(slugs, slug)
+		}
+		groups[slug] = append(groups[slug], t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list tags without slugs: %w", err)
+	}
+
+	merged := 0
+	for _, slug := range slugs {
+		group := groups[slug]
+
+		tx, err := DB.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		// Taken before any tag row, in the same order as other tag merges
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", taxonomyLock); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to lock tag hierarchy: %w", err)
+		}
+
+		// Rows are in ID order, so the first is the oldest unslugged tag
+		survivor, survivorName := group[0].id, group[0].name
+		var slugged int
+		err = tx.QueryRow(ctx, "SELECT id FROM tags WHERE slug = $1 FOR UPDATE", slug).Scan(&slugged)
+		switch {
+		case err == nil:
+			if slugged < survivor {
+				group = append(group, unslugged{id: survivor})
+				survivor = slugged
+			} else {
+				group = append(group, unslugged{id: slugged})
+			}
+		case !errors.Is(err, pgx.ErrNoRows):
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to check tag slug: %w", err)
+		}
+
+		for _, t := range group {
+			if t.id == survivor {
+				continue
+			}
+			if _, _, err := consolidateTag(ctx, tx, t.id, survivor); err != nil {
+				tx.Rollback(ctx)
+				return err
+			}
+			merged++
+		}
+
+		if survivor != slugged {
+			_, err = tx.Exec(ctx, "UPDATE tags SET name = $2, slug = $3 WHERE id = $1", survivor, survivorName, slug)
+			if err != nil {
+				tx.Rollback(ctx)
+				return wrapErr("failed to store tag slug", err)
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit tag slug: %w", err)
+		}
+	}
+
+	if len(slugs) > 0 {
+		logger.Log.Info("Backfilled tag slugs", "slugs", len(slugs), "merged_tags", merged)
+	}
+	return nil
+}
+
+// TagAlias is an alternative name of a canonical tag
+type TagAlias struct {
+	ID        int       `json:"id"`
+	TagID     int       `json:"tag_id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedBy *int      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AliasResult describes the effect of creating an alias
+type AliasResult struct {
+	Alias       TagAlias `json:"alias"`
+	MergedTagID *int     `json:"merged_tag_id"` // Tag that had the alias's slug and was folded into the canonical tag
+	MergedTags  int64    `json:"merged_creator_tags"`
+	MovedVotes  int64    `json:"moved_votes"`
+}
+
+// GetTagAliases lists the aliases of a tag
+func GetTagAliases(ctx context.Context, tagID int) ([]TagAlias, error) {
+	rows, err := DB.Query(ctx, `
+		SELECT id, tag_id, name, slug, created_by, created_at
+		FROM tag_aliases
+		WHERE tag_id = $1
+		ORDER BY name
+	`, tagID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tag aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []TagAlias{}
+	for rows.Next() {
+		var a TagAlias
+		if err := rows.Scan(&a.ID, &a.TagID, &a.Name, &a.Slug, &a.CreatedBy, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tag alias row: %w", err)
+		}
+		aliases = append(aliases, a)
+	}
+
+	return aliases, rows.Err()
+}
+
+// CreateTagAlias makes slug resolve to tagID. A tag already using the slug is
+// folded into tagID with its creator tags and votes, and its own aliases are
+// moved over, all in one transaction.
+func CreateTagAlias(ctx context.Context, tagID int, name, slug string, userID int) (AliasResult, error) {
+	var result AliasResult
+
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Taken before any tag row, like AddTagParent, so the two cannot deadlock
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", taxonomyLock); err != nil {
+		return result, fmt.Errorf("failed to lock tag hierarchy: %w", err)
+	}
+
+	var tagSlug string
+	err = tx.QueryRow(ctx, "SELECT slug FROM tags WHERE id = $1 FOR UPDATE", tagID).Scan(&tagSlug)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return result, fmt.Errorf("tag %d: %w", tagID, ErrNotFound)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to lock tag: %w", err)
+	}
+	if tagSlug == slug {
+		return result, fmt.Errorf("alias %q is the tag's own slug: %w", slug, ErrConflict)
+	}
+
+	var aliasedTagID int
+	err = tx.QueryRow(ctx, "SELECT id FROM tags WHERE slug = $1 FOR UPDATE", slug).Scan(&aliasedTagID)
+	switch {
+	case err == nil:
+		merged, moved, err := consolidateTag(ctx, tx, aliasedTagID, tagID)
+		if err != nil {
+			return result, err
+		}
+		result.MergedTagID = &aliasedTagID
+		result.MergedTags = merged
+		result.MovedVotes = moved
+	case !errors.Is(err, pgx.ErrNoRows):
+		return result, fmt.Errorf("failed to check aliased tag: %w", err)
+	}
+
+	a := &result.Alias
+	err = tx.QueryRow(ctx, `
+		INSERT INTO tag_aliases (tag_id, name, slug, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, tag_id, name, slug, created_by, created_at
+	`, tagID, name, slug, userID).Scan(&a.ID, &a.TagID, &a.Name, &a.Slug, &a.CreatedBy, &a.CreatedAt)
+	if err != nil {
+		return result, wrapErr("failed to create tag alias", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("failed to commit tag alias: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeleteTagAlias stops slug from resolving to tagID. Tags already
+// consolidated by the alias stay merged.
+func DeleteTagAlias(ctx context.Context, tagID int, slug string) error {
+	tag, err := DB.Exec(ctx, "DELETE FROM tag_aliases WHERE tag_id = $1 AND slug = $2", tagID, slug)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag alias: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("alias %q of tag %d: %w", slug, tagID, ErrNotFound)
+	}
+
+	return nil
+}
+
+// consolidateTag folds tag fromID into intoID and deletes it, moving its
+// aliases and hierarchy links over. Callers must hold taxonomyLock before
+// locking any tag. Each creator ends up with one creator tag for intoID: the
+// one with the highest net vote score, which takes over the votes of the
+// others. It returns the number of creator tags merged away and of votes moved.
+func consolidateTag(ctx context.Context, tx pgx.Tx, fromID, intoID int) (int64, int64, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT ct.id, ct.creator_id,
+			(SELECT COALESCE(SUM(v.vote_type), 0) FROM votes v WHERE v.creator_tag_id = ct.id)
+		FROM creator_tags ct
+		WHERE ct.tag_id IN ($1, $2)
+	`, fromID, intoID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list creator tags to consolidate: %w", err)
+	}
+
+	// Everything ends up on intoID, so creator tags collide by creator
+	var candidates []mergeCandidate
+	for rows.Next() {
+		var c mergeCandidate
+		if err := rows.Scan(&c.id, &c.group, &c.score); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan creator tag: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to list creator tags to consolidate: %w", err)
+	}
+
+	var merged, moved int64
+	for _, m := range planTagMerges(candidates) {
+		n, err := mergeCreatorTag(ctx, tx, m.from, m.into)
+		if err != nil {
+			return 0, 0, err
+		}
+		merged++
+		moved += n
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE creator_tags SET tag_id = $2 WHERE tag_id = $1", fromID, intoID); err != nil {
+		return 0, 0, fmt.Errorf("failed to move creator tags: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "UPDATE tag_aliases SET tag_id = $2 WHERE tag_id = $1", fromID, intoID); err != nil {
+		return 0, 0, fmt.Errorf("failed to move tag aliases: %w", err)
+	}
+	if err := moveTagLinks(ctx, tx, fromID, intoID); err != nil {
+		return 0, 0, err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM tags WHERE id = $1", fromID); err != nil {
+		return 0, 0, fmt.Errorf("failed to delete merged tag: %w", err)
+	}
+
+	return merged, moved, nil
+}
+
+// Tag suggestion ranking. Similarity is in [0, 1]; usage adds up to
+// suggestUsageWeight on a saturating log scale, and the share of similar
+// creators using a tag adds up to suggestContextWeight.
+const (
+	suggestUsageWeight   = 0.3
+	suggestContextWeight = 0.5
+	// suggestNeighbors is how many creators sharing the most tags with the
+	// context creator are considered similar
+	suggestNeighbors = 50
+)
+
+// TagSuggestion is a tag matching an autocomplete query
+type TagSuggestion struct {
+	Tag
+	MatchedAlias *string `json:"matched_alias"` // Set when the query matched an alias rather than the name
+	UsageCount   int     `json:"usage_count"`
+	Similarity   float64 `json:"similarity"`
+	Score        float64 `json:"score"`
+}
+
+// SuggestTags returns up to limit tags whose name or an alias is similar to
+// or starts with q, best first. With a creatorID, tags already on that
+// creator are left out and tags common among creators sharing its tags rank
+// higher.
+func SuggestTags(ctx context.Context, q string, creatorID *int, limit int) ([]TagSuggestion, error) {
+	rows, err := DB.Query(ctx, `
+		WITH candidates AS (
+			SELECT t.id AS tag_id, similarity(t.name, $1) AS sim, NULL::text AS alias
+			FROM tags t
+			WHERE t.name % $1 OR t.name ILIKE $2
+			UNION ALL
+			SELECT a.tag_id, similarity(a.name, $1), a.name
+			FROM tag_aliases a
+			WHERE a.name % $1 OR a.name ILIKE $2
+		),
+		best AS (
+			SELECT DISTINCT ON (tag_id) tag_id, sim, alias
+			FROM candidates
+			ORDER BY tag_id, sim DESC, alias NULLS FIRST
+		),
+		context_tags AS (
+			SELECT tag_id FROM creator_tags WHERE creator_id = $3
+		),
+		neighbors AS (
+			SELECT ct.creator_id
+			FROM creator_tags ct
+			JOIN creators c ON c.id = ct.creator_id AND c.deleted_at IS NULL
+			WHERE ct.tag_id IN (SELECT tag_id FROM context_tags) AND ct.creator_id <> $3
+			GROUP BY ct.creator_id
+			ORDER BY count(DISTINCT ct.tag_id) DESC, ct.creator_id
+			LIMIT $5
+		),
+		neighbor_usage AS (
+			SELECT ct.tag_id, count(DISTINCT ct.creator_id)::float8 / (SELECT GREATEST(count(*), 1) FROM neighbors) AS share
+			FROM creator_tags ct
+			JOIN neighbors n ON n.creator_id = ct.creator_id
+			GROUP BY ct.tag_id
+		),
+		scored AS (
+			SELECT t.id, t.name, t.slug, b.alias, b.sim,
+				(SELECT count(*) FROM creator_tags WHERE tag_id = t.id) AS usage,
+				COALESCE(nu.share, 0) AS share
+			FROM best b
+			JOIN tags t ON t.id = b.tag_id
+			LEFT JOIN neighbor_usage nu ON nu.tag_id = t.id
+			WHERE t.id NOT IN (SELECT tag_id FROM context_tags)
+		)
+		SELECT id, name, slug, alias, usage, sim,
+			sim + $6 * ln(1 + usage) / (1 + ln(1 + usage)) + $7 * share AS score
+		FROM scored
+		ORDER BY score DESC, usage DESC, name
+		LIMIT $4
+	`, q, escapeLike(q)+"%", creatorID, limit, suggestNeighbors, suggestUsageWeight, suggestContextWeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest tags: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []TagSuggestion{}
+	for rows.Next() {
+		var s TagSuggestion
+		if err := rows.Scan(&s.ID, &s.Name, &s.Slug, &s.MatchedAlias, &s.UsageCount, &s.Similarity, &s.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan tag suggestion: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, rows.Err()
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,258 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxTagDepth bounds how far descendant lookups walk down the tag hierarchy
+const MaxTagDepth = 10
+
+// ErrCycle is returned when a parent link would make a tag its own ancestor
+var ErrCycle = errors.New("tag hierarchy would contain a cycle")
+
+// taxonomyLock is the advisory lock key serializing hierarchy changes, so two
+// concurrent links cannot form a cycle that neither sees on its own
+const taxonomyLock = 7_140_001
+
+// Tag is a canonical tag
+type Tag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// TagNode is a tag found below another, at its shortest distance
+type TagNode struct {
+	Tag
+	Depth int `json:"depth"`
+}
+
+// GetTag fetches a tag by ID
+func GetTag(ctx context.Context, id int) (Tag, error) {
+	var t Tag
+	err := DB.QueryRow(ctx, "SELECT id, name, slug FROM tags WHERE id = $1", id).Scan(&t.ID, &t.Name, &t.Slug)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Tag{}, fmt.Errorf("tag %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return Tag{}, fmt.Errorf("failed to fetch tag: %w", err)
+	}
+
+	return t, nil
+}
+
+// GetTagParents lists the direct parents of a tag
+func GetTagParents(ctx context.Context, id int) ([]Tag, error) {
+	return queryTags(ctx, `
+		SELECT t.id, t.name, t.slug
+		FROM tag_parents tp
+		JOIN tags t ON t.id = tp.parent_id
+		WHERE tp.tag_id = $1
+		ORDER BY t.name
+	`, id)
+}
+
+// GetTagChildren lists the direct children of a tag
+func GetTagChildren(ctx context.Context, id int) ([]Tag, error) {
+	return queryTags(ctx, `
+		SELECT t.id, t.name, t.slug
+		FROM tag_parents tp
+		JOIN tags t ON t.id = tp.tag_id
+		WHERE tp.parent_id = $1
+		ORDER BY t.name
+	`, id)
+}
+
+// GetRootTags lists the tops of the hierarchy: tags with children but no parents
+func GetRootTags(ctx context.Context) ([]Tag, error) {
+	return queryTags(ctx, `
+		SELECT t.id, t.name, t.slug
+		FROM tags t
+		WHERE EXISTS (SELECT 1 FROM tag_parents WHERE parent_id = t.id)
+			AND NOT EXISTS (SELECT 1 FROM tag_parents WHERE tag_id = t.id)
+		ORDER BY t.name
+	`)
+}
+
+// GetTagDescendants lists every tag below a tag, up to MaxTagDepth levels,
+// nearest first
+func GetTagDescendants(ctx context.Context, id int) ([]TagNode, error) {
+	rows, err := DB.Query(ctx, `
+		WITH RECURSIVE below AS (
+			SELECT tag_id AS id, 1 AS depth FROM tag_parents WHERE parent_id = $1
+			UNION ALL
+			SELECT tp.tag_id, b.depth + 1
+			FROM tag_parents tp
+			JOIN below b ON tp.parent_id = b.id
+			WHERE b.depth < $2
+		)
+		SELECT t.id, t.name, t.slug, min(b.depth) AS depth
+		FROM below b
+		JOIN tags t ON t.id = b.id
+		GROUP BY t.id
+		ORDER BY depth, t.name
+	`, id, MaxTagDepth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tag descendants: %w", err)
+	}
+	defer rows.Close()
+
+	nodes := []TagNode{}
+	for rows.Next() {
+		var n TagNode
+		if err := rows.Scan(&n.ID, &n.Name, &n.Slug, &n.Depth); err != nil {
+			return nil, fmt.Errorf("failed to scan tag row: %w", err)
+		}
+		nodes = append(nodes, n)
+	}
+
+	return nodes, rows.Err()
+}
+
+// AddTagParent makes parentID a parent of tagID, refusing links that would
+// form a cycle
+func AddTagParent(ctx context.Context, tagID, parentID int) error {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", taxonomyLock); err != nil {
+		return fmt.Errorf("failed to lock tag hierarchy: %w", err)
+	}
+
+	var found int
+	err = tx.QueryRow(ctx, "SELECT count(*) FROM tags WHERE id IN ($1, $2)", tagID, parentID).Scan(&found)
+	if err != nil {
+		return fmt.Errorf("failed to check tags: %w", err)
+	}
+	if found != 2 {
+		return fmt.Errorf("link tag %d to %d: %w", tagID, parentID, ErrNotFound)
+	}
+
+	inserted, err := linkTags(ctx, tx, tagID, parentID)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		return fmt.Errorf("tag %d already has parent %d: %w", tagID, parentID, ErrConflict)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tag parent: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveTagParent unlinks a tag from one of its parents
+func RemoveTagParent(ctx context.Context, tagID, parentID int) error {
+	tag, err := DB.Exec(ctx, "DELETE FROM tag_parents WHERE tag_id = $1 AND parent_id = $2", tagID, parentID)
+	if err != nil {
+		return fmt.Errorf("failed to remove tag parent: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("tag %d has no parent %d: %w", tagID, parentID, ErrNotFound)
+	}
+
+	return nil
+}
+
+// linkTags inserts a parent link unless it already exists, returning whether
+// it was inserted, or ErrCycle when parentID is tagID or one of its
+// descendants. Callers must hold the taxonomy lock.
+func linkTags(ctx context.Context, tx pgx.Tx, tagID, parentID int) (bool, error) {
+	var cycle bool
+	err := tx.QueryRow(ctx, `
+		WITH RECURSIVE below AS (
+			SELECT $1::int AS id
+			UNION
+			SELECT tp.tag_id FROM tag_parents tp JOIN below b ON tp.parent_id = b.id
+		)
+		SELECT EXISTS (SELECT 1 FROM below WHERE id = $2)
+	`, tagID, parentID).Scan(&cycle)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for tag cycle: %w", err)
+	}
+	if cycle {
+		return false, fmt.Errorf("tag %d below %d: %w", tagID, parentID, ErrCycle)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO tag_parents (tag_id, parent_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, tagID, parentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to link tags: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// moveTagLinks hands the parent and child links of fromID to intoID before
+// fromID is merged away. Links that would form a cycle are dropped.
+func moveTagLinks(ctx context.Context, tx pgx.Tx, fromID, intoID int) error {
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", taxonomyLock); err != nil {
+		return fmt.Errorf("failed to lock tag hierarchy: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		DELETE FROM tag_parents WHERE tag_id = $1 OR parent_id = $1
+		RETURNING tag_id, parent_id
+	`, fromID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink merged tag: %w", err)
+	}
+
+	type link struct{ tagID, parentID int }
+	var links []link
+	for rows.Next() {
+		var l link
+		if err := rows.Scan(&l.tagID, &l.parentID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan tag link: %w", err)
+		}
+		links = append(links, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to unlink merged tag: %w", err)
+	}
+
+	for _, l := range links {
+		if l.tagID == fromID {
+			l.tagID = intoID
+		} else {
+			l.parentID = intoID
+		}
+		if _, err := linkTags(ctx, tx, l.tagID, l.parentID); err != nil && !errors.Is(err, ErrCycle) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func queryTags(ctx context.Context, query string, args ...interface{}) ([]Tag, error) {
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.Slug); err != nil {
+			return nil, fmt.Errorf("failed to scan tag row: %w", err)
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Video is a stored upload of a creator
+type Video struct {
+	ID              int       `json:"id"`
+	CreatorID       int       `json:"creator_id"`
+	YouTubeVideoID  string    `json:"youtube_video_id"`
+	Title           string    `json:"title"`
+	PublishedAt     time.Time `json:"published_at"`
+	DurationSeconds int       `json:"duration_seconds"`
+	ThumbnailURL    string    `json:"thumbnail_url"`
+}
+
+// VideoCursor marks a position in a creator's newest-first video list
+type VideoCursor struct {
+	PublishedAt time.Time `json:"p"`
+	ID          int       `json:"i"`
+}
+
+// UpsertVideos stores videos for a creator, updating ones that already exist
+func UpsertVideos(ctx context.Context, creatorID int, videos []Video) error {
+	batch := &pgx.Batch{}
+	for _, v := range videos {
+		batch.Queue(`
+			INSERT INTO videos (creator_id, youtube_video_id, title, published_at, duration_seconds, thumbnail_url)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+			ON CONFLICT (youtube_video_id) DO UPDATE SET
+				title = EXCLUDED.title,
+				duration_seconds = EXCLUDED.duration_seconds,
+				thumbnail_url = EXCLUDED.thumbnail_url
+		`, creatorID, v.YouTubeVideoID, v.Title, v.PublishedAt, v.DurationSeconds, v.ThumbnailURL)
+	}
+
+	if err := DB.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to store videos: %w", err)
+	}
+
+	return nil
+}
+
+// LatestVideoPublishedAt returns the publish time of a creator's newest stored
+// video, or the zero time if none are stored
+func LatestVideoPublishedAt(ctx context.Context, creatorID int) (time.Time, error) {
+	var latest *time.Time
+	err := DB.QueryRow(ctx, "SELECT max(published_at) FROM videos WHERE creator_id = $1", creatorID).Scan(&latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch latest video: %w", err)
+	}
+	if latest == nil {
+		return time.Time{}, nil
+	}
+
+	return *latest, nil
+}
+
+// ListVideos returns up to limit videos of a creator, newest first, starting
+// after the given cursor. The returned cursor is nil on the last page.
+func ListVideos(ctx context.Context, creatorID int, after *VideoCursor, limit int) ([]Video, *VideoCursor, error) {
+	// Far-future sentinel so the first page needs no separate query
+	cursor := VideoCursor{PublishedAt: time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if after != nil {
+		cursor = *after
+	}
+
+	rows, err := DB.Query(ctx, `
+		SELECT id, creator_id, youtube_video_id, title, published_at, duration_seconds, COALESCE(thumbnail_url, '')
+		FROM videos
+		WHERE creator_id = $1 AND (published_at, id) < ($2, $3)
+		ORDER BY published_at DESC, id DESC
+		LIMIT $4
+	`, creatorID, cursor.PublishedAt, cursor.ID, limit+1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+	defer rows.Close()
+
+	videos := []Video{}
+	for rows.Next() {
+		var v Video
+		if err := rows.Scan(&v.ID, &v.CreatorID, &v.YouTubeVideoID, &v.Title, &v.PublishedAt, &v.DurationSeconds, &v.ThumbnailURL); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan video row: %w", err)
+		}
+		videos = append(videos, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	if len(videos) <= limit {
+		return videos, nil, nil
+	}
+
+	videos = videos[:limit]
+	last := videos[limit-1]
+	return videos, &VideoCursor{PublishedAt: last.PublishedAt, ID: last.ID}, nil
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// YouTubeStore persists YouTube quota usage and cached API lookups
+type YouTubeStore struct{}
+
+// AddQuotaUsage adds units spent on an endpoint to a quota day's total
+func (YouTubeStore) AddQuotaUsage(ctx context.Context, day, endpoint string, units int) error {
+	_, err := DB.Exec(ctx, `
+		INSERT INTO youtube_quota_usage (day, endpoint, units, calls)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (day, endpoint)
+		DO UPDATE SET units = youtube_quota_usage.units + EXCLUDED.units, calls = youtube_quota_usage.calls + 1
+	`, day, endpoint, units)
+	if err != nil {
+		return fmt.Errorf("failed to record quota usage: %w", err)
+	}
+
+	return nil
+}
+
+// QuotaUsage returns the units spent per endpoint on a quota day
+func (YouTubeStore) QuotaUsage(ctx context.Context, day string) (map[string]int, error) {
+	rows, err := DB.Query(ctx, "SELECT endpoint, units FROM youtube_quota_usage WHERE day = $1", day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch quota usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := map[string]int{}
+	for rows.Next() {
+		var endpoint string
+		var units int
+		if err := rows.Scan(&endpoint, &units); err != nil {
+			return nil, fmt.Errorf("failed to scan quota usage row: %w", err)
+		}
+		usage[endpoint] = units
+	}
+
+	return usage, rows.Err()
+}
+
+// GetCached returns an unexpired cached value
+func (YouTubeStore) GetCached(ctx context.Context, key string) ([]byte, time.Time, bool, error) {
+	var value []byte
+	var expiresAt time.Time
+	err := DB.QueryRow(ctx, `
+		SELECT value, expires_at FROM youtube_cache WHERE key = $1 AND expires_at > now()
+	`, key).Scan(&value, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, time.Time{}, false, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	return value, expiresAt, true, nil
+}
+
+// PutCached stores a JSON value until expiresAt, replacing any previous value
+func (YouTubeStore) PutCached(ctx context.Context, key string, value []byte, expiresAt time.Time) error {
+	_, err := DB.Exec(ctx, `
+		INSERT INTO youtube_cache (key, value, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at
+	`, key, value, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+// Package evaluate compares recommendation strategies offline: it replays a
+// snapshot of tag and vote events, trains each strategy on the events before
+// a cutoff and checks its recommendations against what users liked after it.
+package evaluate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/recommend"
+)
+
+// Snapshot is a fixed set of events to evaluate on
+type Snapshot struct {
+	TakenAt time.Time         `json:"taken_at"`
+	Events  []recommend.Event `json:"events"`
+}
+
+// ReadSnapshot loads a snapshot written by WriteSnapshot
+func ReadSnapshot(path string) (Snapshot, error) {
+	var snapshot Snapshot
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+
+	sortEvents(snapshot.Events)
+	return snapshot, nil
+}
+
+// WriteSnapshot saves a snapshot as JSON
+func WriteSnapshot(path string, snapshot Snapshot) error {
+	sortEvents(snapshot.Events)
+
+	raw, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// sortEvents orders events by time, with a fixed order for simultaneous ones
+func sortEvents(events []recommend.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		switch {
+		case !a.At.Equal(b.At):
+			return a.At.Before(b.At)
+		case a.UserID != b.UserID:
+			return a.UserID < b.UserID
+		case a.CreatorID != b.CreatorID:
+			return a.CreatorID < b.CreatorID
+		case a.TagID != b.TagID:
+			return a.TagID < b.TagID
+		default:
+			return a.Kind < b.Kind
+		}
+	})
+}
+
+// Cutoff returns the time before which the given fraction of the events
+// happened. Events must be sorted by time.
+func Cutoff(events []recommend.Event, trainFraction float64) time.Time {
+	if len(events) == 0 {
+		return time.Time{}
+	}
+	i := int(math.Round(trainFraction * float64(len(events))))
+	i = max(1, min(i, len(events)-1))
+	return events[i].At
+}
+
+// Options controls Run
+type Options struct {
+	K          int       // Length of each recommendation list
+	Cutoff     time.Time // Events before it train the strategies, the rest test them
+	Strategies []string  // Names of registered strategies to run
+	Strategy   recommend.StrategyOptions
+}
+
+// Result holds the metrics of one strategy, averaged over evaluated users
+// except for coverage
+type Result struct {
+	Strategy  string  `json:"strategy"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	NDCG      float64 `json:"ndcg"`
+	Coverage  float64 `json:"coverage"` // Share of known creators recommended to anyone
+	Novelty   float64 `json:"novelty"`  // Mean self-information, in bits, of recommended creators
+}
+
+// Report is the outcome of Run
+type Report struct {
+	K           int       `json:"k"`
+	Cutoff      time.Time `json:"cutoff"`
+	TrainEvents int       `json:"train_events"`
+	TestEvents  int       `json:"test_events"`
+	Users       int       `json:"users"` // Users with training data and creators to find
+	Creators    int       `json:"creators"`
+	Results     []Result  `json:"results"`
+}
+
+// Run splits events at opts.Cutoff, fits each strategy on the earlier events
+// and scores its top-K lists against the creators each user upvoted or tagged
+// after the cutoff without having interacted with them before
+func Run(events []recommend.Event, opts Options) (Report, error) {
+	var train, test []recommend.Event
+	for _, e := range events {
+		if e.At.Before(opts.Cutoff) {
+			train = append(train, e)
+		} else {
+			test = append(test, e)
+		}
+	}
+
+	ds := recommend.BuildDataset(train)
+	relevant := relevantCreators(ds, test)
+
+	users := make([]int, 0, len(relevant))
+	for userID := range relevant {
+		users = append(users, userID)
+	}
+	sort.Ints(users)
+
+	catalog := map[int]bool{}
+	for creatorID := range ds.TagVectors {
+		catalog[creatorID] = true
+	}
+	for creatorID := range ds.Popularity {
+		catalog[creatorID] = true
+	}
+
+	report := Report{
+		K:           opts.K,
+		Cutoff:      opts.Cutoff,
+		TrainEvents: len(train),
+		TestEvents:  len(test),
+		Users:       len(users),
+		Creators:    len(catalog),
+	}
+
+	for _, name := range opts.Strategies {
+		strategy, err := recommend.NewStrategy(name, opts.Strategy)
+		if err != nil {
+			return Report{}, err
+		}
+		strategy.Fit(ds)
+		report.Results = append(report.Results, score(name, strategy, ds, users, relevant, len(catalog), opts.K))
+	}
+
+	return report, nil
+}
+
+// relevantCreators returns, per user with training data, the creators the
+// user upvoted or tagged in the test events and had not interacted with in
+// the training events
+func relevantCreators(ds *recommend.Dataset, test []recommend.Event) map[int]map[int]bool {
+	relevant := map[int]map[int]bool{}
+	for _, e := range test {
+		if e.Kind == recommend.EventDownvote || ds.Seen[e.UserID] == nil || ds.Seen[e.UserID][e.CreatorID] {
+			continue
+		}
+		if relevant[e.UserID] == nil {
+			relevant[e.UserID] = map[int]bool{}
+		}
+		relevant[e.UserID][e.CreatorID] = true
+	}
+	return relevant
+}
+
+// score computes the metrics of one fitted strategy
+func score(name string, strategy recommend.Strategy, ds *recommend.Dataset, users []int, relevant map[int]map[int]bool, catalog, k int) Result {
+	result := Result{Strategy: name}
+	recommended := map[int]bool{}
+	var novelty float64
+	var recommendations int
+
+	for _, userID := range users {
+		list := strategy.Recommend(userID, k)
+		wanted := relevant[userID]
+
+		hits := 0
+		dcg := 0.0
+		for rank, creatorID := range list {
+			recommended[creatorID] = true
+			if wanted[creatorID] {
+				hits++
+				dcg += 1 / math.Log2(float64(rank+2))
+			}
+
+			// Smoothed so creators nobody interacted with stay finite
+			share := float64(ds.Popularity[creatorID]+1) / float64(ds.Users+1)
+			novelty -= math.Log2(share)
+			recommendations++
+		}
+
+		idcg := 0.0
+		for rank := 0; rank < min(len(wanted), k); rank++ {
+			idcg += 1 / math.Log2(float64(rank+2))
+		}
+
+		result.Precision += float64(hits) / float64(k)
+		result.Recall += float64(hits) / float64(len(wanted))
+		if idcg > 0 {
+			result.NDCG += dcg / idcg
+		}
+	}
+
+	if len(users) > 0 {
+		result.Precision /= float64(len(users))
+		result.Recall /= float64(len(users))
+		result.NDCG /= float64(len(users))
+	}
+	if catalog > 0 {
+		result.Coverage = float64(len(recommended)) / float64(catalog)
+	}
+	if recommendations > 0 {
+		result.Novelty = novelty / float64(recommendations)
+	}
+	return result
+}
+
+// WriteJSON writes a report as indented JSON
+func WriteJSON(w io.Writer, report Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// WriteTable writes a report as a plain text table
+func WriteTable(w io.Writer, report Report) error {
+	fmt.Fprintf(w, "cutoff %s, %d train / %d test events, %d users, %d creators\n\n",
+		report.Cutoff.Format(time.RFC3339), report.TrainEvents, report.TestEvents, report.Users, report.Creators)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "strategy\tprecision@%[1]d\trecall@%[1]d\tndcg@%[1]d\tcoverage\tnovelty\t\n", report.K)
+	for _, r := range report.Results {
+		fmt.Fprintf(tw, "%s\t%.4f\t%.4f\t%.4f\t%.4f\t%.2f\t\n", r.Strategy, r.Precision, r.Recall, r.NDCG, r.Coverage, r.Novelty)
+	}
+	return tw.Flush()
+}
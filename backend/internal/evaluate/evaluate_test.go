@@ -0,0 +1,117 @@
+package evaluate
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testdata/snapshot.json trains on six events before January 10th:
+//
+//   - creator 1 is tagged by user 1 and upvoted by users 2 and 3
+//   - creator 2 is tagged by user 2 and upvoted by user 4
+//   - creator 3 is tagged by user 3 with another tag
+//
+// After the cutoff user 1 upvotes creator 2 and tags creator 4, which is
+// unknown when training, and user 2 upvotes creator 3. The rest are a
+// downvote, a creator the user already upvoted and a user without training
+// data, none of which count.
+func loadSnapshot(t *testing.T) Snapshot {
+	t.Helper()
+	snap, err := ReadSnapshot("testdata/snapshot.json")
+	if err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+	return snap
+}
+
+func TestCutoff(t *testing.T) {
+	snap := loadSnapshot(t)
+
+	want := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	if got := Cutoff(snap.Events, 0.5); !got.Equal(want) {
+		t.Errorf("Cutoff = %s, want %s", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	snap := loadSnapshot(t)
+
+	report, err := Run(snap.Events, Options{
+		K:          2,
+		Cutoff:     time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		Strategies: []string{"popularity", "tag_profile"},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if report.TrainEvents != 6 || report.TestEvents != 6 {
+		t.Errorf("got %d train and %d test events, want 6 and 6", report.TrainEvents, report.TestEvents)
+	}
+	// Users 1 and 2, and creators 1 to 3
+	if report.Users != 2 || report.Creators != 3 {
+		t.Errorf("got %d users and %d creators, want 2 and 3", report.Users, report.Creators)
+	}
+
+	// Novelty smooths popularity over the 4 training users: creator 2 has 2
+	// of them and creator 3 has 1
+	novelty2 := math.Log2(5.0 / 3)
+	novelty3 := math.Log2(5.0 / 2)
+
+	want := []Result{
+		{
+			// User 1 gets creators 2 and 3, hitting 2 first of its 2 wanted.
+			// User 2 gets only creator 3 and hits it.
+			Strategy:  "popularity",
+			Precision: (1.0/2 + 1.0/2) / 2,
+			Recall:    (1.0/2 + 1) / 2,
+			NDCG:      (1/(1+1/math.Log2(3)) + 1) / 2,
+			Coverage:  2.0 / 3,
+			Novelty:   (novelty2 + 2*novelty3) / 3,
+		},
+		{
+			// User 1 only has the tag of creator 2, so gets only it. Every
+			// creator with user 2's tag is one user 2 has seen.
+			Strategy:  "tag_profile",
+			Precision: (1.0/2 + 0) / 2,
+			Recall:    (1.0/2 + 0) / 2,
+			NDCG:      (1/(1+1/math.Log2(3)) + 0) / 2,
+			Coverage:  1.0 / 3,
+			Novelty:   novelty2,
+		},
+	}
+
+	if len(report.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(report.Results), len(want))
+	}
+	for i, got := range report.Results {
+		w := want[i]
+		if got.Strategy != w.Strategy {
+			t.Errorf("result %d is %s, want %s", i, got.Strategy, w.Strategy)
+			continue
+		}
+		metrics := []struct {
+			name      string
+			got, want float64
+		}{
+			{"precision", got.Precision, w.Precision},
+			{"recall", got.Recall, w.Recall},
+			{"ndcg", got.NDCG, w.NDCG},
+			{"coverage", got.Coverage, w.Coverage},
+			{"novelty", got.Novelty, w.Novelty},
+		}
+		for _, m := range metrics {
+			if math.Abs(m.got-m.want) > 1e-9 {
+				t.Errorf("%s %s = %v, want %v", got.Strategy, m.name, m.got, m.want)
+			}
+		}
+	}
+}
+
+func TestRunUnknownStrategy(t *testing.T) {
+	_, err := Run(loadSnapshot(t).Events, Options{K: 2, Strategies: []string{"nope"}})
+	if err == nil {
+		t.Fatal("expected an error for an unknown strategy")
+	}
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
+	"github.com/gin-gonic/gin"
+)
+
+// GetQuotaUsage returns the YouTube API quota spent in the current quota day
+func GetQuotaUsage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	c.JSON(http.StatusOK, youtube.Quota.Usage(ctx))
+}
+
+// respondQuotaExceeded answers with 503 and a Retry-After header for when
+// the YouTube quota budget is available again
+func respondQuotaExceeded(c *gin.Context, err *youtube.QuotaExceededError) {
+	c.Header("Retry-After", strconv.Itoa(int(err.RetryAfter.Seconds())))
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": "YouTube API quota exhausted, try again later"})
+}
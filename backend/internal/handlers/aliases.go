@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTagAliases lists the aliases of a tag
+func GetTagAliases(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	aliases, err := db.GetTagAliases(ctx, tagID)
+	if err != nil {
+		logger.Log.Error("Failed to fetch tag aliases", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag aliases"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"aliases": aliases})
+}
+
+// CreateTagAlias makes an alias resolve to a tag, folding any existing tag
+// with the alias's name into it
+func CreateTagAlias(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	var request struct {
+		Alias string `json:"alias" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	alias, ok := normalizeTag(c, request.Alias)
+	if !ok {
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := db.CreateTagAlias(ctx, tagID, alias.Name, alias.Slug, userID.(int))
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		case errors.Is(err, db.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Alias already exists or is the tag's own name"})
+		default:
+			logger.Log.Error("Failed to create tag alias", "tag_id", tagID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag alias"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// DeleteTagAlias removes an alias from a tag
+func DeleteTagAlias(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	alias, ok := normalizeTag(c, c.Param("alias"))
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := db.DeleteTagAlias(ctx, tagID, alias.Slug); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alias not found"})
+			return
+		}
+		logger.Log.Error("Failed to delete tag alias", "tag_id", tagID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag alias"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alias removed"})
+}
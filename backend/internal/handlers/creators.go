@@ -2,18 +2,21 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
-	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/ingest"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
 	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
 
-// AddCreator handles adding a new YouTube creator by @handle
+// AddCreator handles adding a new YouTube creator by @handle, channel URL or channel ID
 func AddCreator(c *gin.Context) {
 	var request struct {
 		YouTubeHandle string `json:"youtube_handle" binding:"required"`
@@ -24,118 +27,357 @@ func AddCreator(c *gin.Context) {
 		return
 	}
 
-	// Fetch channel details from YouTube API using @handle
-	channelID, name, description, err := fetchYouTubeChannelDetails(request.YouTubeHandle)
+	ref, err := youtube.ParseChannelRef(request.YouTubeHandle)
 	if err != nil {
-		logger.Log.Error("Failed to fetch YouTube channel details", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch channel details"})
+		response := gin.H{"error": "Expected an @handle, a youtube.com channel URL or a channel ID"}
+		var refErr *youtube.RefError
+		if errors.As(err, &refErr) {
+			response["reason"] = refErr.Reason
+		}
+		c.JSON(http.StatusUnprocessableEntity, response)
 		return
 	}
 
-	// Store creator in DB using pgxpool
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	creatorID, err := db.AddCreator(ctx, request.YouTubeHandle, channelID, name, description)
+	// Resolve the channel exactly with the YouTube channels endpoint
+	channel, err := youtube.API.ResolveChannel(ctx, ref)
 	if err != nil {
+		var quotaErr *youtube.QuotaExceededError
+		switch {
+		case errors.Is(err, youtube.ErrNotFound):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("No YouTube channel found for %s", ref)})
+		case errors.Is(err, youtube.ErrAmbiguous):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("%s matches more than one YouTube channel, use the channel URL or ID instead", ref)})
+		case errors.As(err, &quotaErr):
+			respondQuotaExceeded(c, quotaErr)
+		default:
+			logger.Log.Error("Failed to fetch YouTube channel details", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch channel details"})
+		}
+		return
+	}
+
+	// Store creator in DB using pgxpool, refreshing it if it already exists
+	creator, created, err := ingest.AddChannel(ctx, channel)
+	if err != nil {
+		// Existing handles are taken over, so this only happens when another
+		// request stores the same handle for a different channel concurrently
+		if errors.Is(err, db.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Handle is already used by another creator"})
+			return
+		}
 		logger.Log.Error("Failed to store creator", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store creator"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"id":             creatorID,
-		"youtube_handle": request.YouTubeHandle,
-		"youtube_id":     channelID,
-		"name":           name,
-		"description":    description,
-	})
+	if creator.DeletedAt != nil {
+		c.JSON(http.StatusGone, gin.H{
+			"error":          "Creator was removed by a moderator",
+			"creator_id":     creator.ID,
+			"merged_into_id": creator.MergedIntoID,
+		})
+		return
+	}
+
+	// Pull recent uploads; the creator is stored even if this fails
+	if _, err := ingest.SyncUploads(ctx, youtube.API, creator.ID, creator.UploadsPlaylistID); err != nil {
+		logger.Log.Error("Failed to sync uploads", "creator_id", creator.ID, "error", err)
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, creator)
+}
+
+// defaultSortDesc lists the sort orders that default to descending
+var defaultSortDesc = map[string]bool{
+	db.SortByCreatedAt:   true,
+	db.SortByScore:       true,
+	db.SortBySubscribers: true,
+}
+
+// ListCreators returns a page of creators sorted by name, date added, total
+// tag score or subscriber count, optionally filtered by tag and minimum score
+func ListCreators(c *gin.Context) {
+	opts := db.CreatorListOptions{
+		Sort: c.DefaultQuery("sort", db.SortByName),
+	}
+
+	if raw := c.Query("tag"); raw != "" {
+		tag, ok := normalizeTag(c, raw)
+		if !ok {
+			return
+		}
+		opts.Tag = tag.Slug
+	}
+
+	if !db.ValidCreatorSort(opts.Sort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort, must be name, created_at, score or subscribers"})
+		return
+	}
+
+	switch c.DefaultQuery("order", "") {
+	case "":
+		opts.Desc = defaultSortDesc[opts.Sort]
+	case "asc":
+		opts.Desc = false
+	case "desc":
+		opts.Desc = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order, must be asc or desc"})
+		return
+	}
+
+	if raw := c.Query("min_score"); raw != "" {
+		minScore, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_score"})
+			return
+		}
+		opts.MinScore = &minScore
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	opts.Limit = limit
+
+	if token := c.Query("cursor"); token != "" {
+		opts.After = &db.CreatorCursor{}
+		if err := decodeCursor(token, opts.After); err != nil || opts.After.Sort != opts.Sort {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	creators, next, err := db.ListCreators(ctx, opts)
+	if err != nil {
+		logger.Log.Error("Failed to list creators", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list creators"})
+		return
+	}
+
+	var nextCursor *string
+	if next != nil {
+		token := encodeCursor(next)
+		nextCursor = &token
+	}
+
+	c.JSON(http.StatusOK, gin.H{"creators": creators, "next_cursor": nextCursor})
 }
 
 // GetCreator retrieves a creator's details
 func GetCreator(c *gin.Context) {
-	creatorID := c.Param("id")
+	creatorID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	creator, err := db.GetCreator(ctx, creatorID)
 	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Creator not found"})
+			return
+		}
 		logger.Log.Error("Failed to fetch creator", "error", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Creator not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch creator"})
 		return
 	}
 
 	c.JSON(http.StatusOK, creator)
 }
 
-// Fetch YouTube channel details using YouTube API with @handle support
-func fetchYouTubeChannelDetails(youtubeHandle string) (string, string, string, error) {
-	apiKey := config.AppConfig.YouTube.APIKey
+// GetCreatorByHandle looks a creator up by its current or a previous handle.
+// Old handles and handles of merged duplicates resolve to the current
+// creator with redirected set.
+func GetCreatorByHandle(c *gin.Context) {
+	handle := strings.TrimSpace(c.Param("handle"))
+	if handle == "" || handle == "@" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid handle"})
+		return
+	}
+	if !strings.HasPrefix(handle, "@") {
+		handle = "@" + handle
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
 
-	// Remove '@' from handle if present
-	if youtubeHandle[0] == '@' {
-		youtubeHandle = youtubeHandle[1:]
+	creator, match, err := db.GetCreatorByHandle(ctx, handle)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Creator not found"})
+			return
+		}
+		logger.Log.Error("Failed to fetch creator by handle", "handle", handle, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch creator"})
+		return
 	}
 
-	// Step 1: Get channel ID from @handle
-	searchURL := fmt.Sprintf(
-		"https://www.googleapis.com/youtube/v3/search?part=snippet&type=channel&q=%s&key=%s",
-		youtubeHandle, apiKey,
-	)
+	c.JSON(http.StatusOK, gin.H{
+		"creator":          creator,
+		"requested_handle": handle,
+		"redirected":       match != db.HandleCurrent,
+		"match":            match,
+	})
+}
 
-	resp, err := http.Get(searchURL)
+// UpdateCreator corrects a creator's name, description or handle
+func UpdateCreator(c *gin.Context) {
+	creatorID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return "", "", "", err
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+		return
 	}
-	defer resp.Body.Close()
 
-	var searchResult struct {
-		Items []struct {
-			ID struct {
-				ChannelID string `json:"channelId"`
-			} `json:"id"`
-		} `json:"items"`
+	var request struct {
+		Name          *string `json:"name"`
+		Description   *string `json:"description"`
+		YouTubeHandle *string `json:"youtube_handle"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&searchResult); err != nil {
-		return "", "", "", err
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if request.Name == nil && request.Description == nil && request.YouTubeHandle == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update, set name, description or youtube_handle"})
+		return
+	}
+	if request.Name != nil && strings.TrimSpace(*request.Name) == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Name cannot be empty"})
+		return
 	}
 
-	if len(searchResult.Items) == 0 {
-		return "", "", "", fmt.Errorf("channel not found for handle: @%s", youtubeHandle)
+	update := db.CreatorUpdate{Name: request.Name, Description: request.Description}
+	if request.YouTubeHandle != nil {
+		handle := strings.TrimSpace(*request.YouTubeHandle)
+		if handle != "" && !strings.HasPrefix(handle, "@") {
+			handle = "@" + handle
+		}
+		update.YouTubeHandle = &handle
 	}
 
-	channelID := searchResult.Items[0].ID.ChannelID
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	creator, err := db.UpdateCreator(ctx, creatorID, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Creator not found"})
+		case errors.Is(err, db.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Handle is already used by another creator"})
+		default:
+			logger.Log.Error("Failed to update creator", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update creator"})
+		}
+		return
+	}
 
-	// Step 2: Get channel details using Channel ID
-	detailsURL := fmt.Sprintf(
-		"https://www.googleapis.com/youtube/v3/channels?part=snippet&id=%s&key=%s",
-		channelID, apiKey,
-	)
+	c.JSON(http.StatusOK, creator)
+}
 
-	resp, err = http.Get(detailsURL)
+// DeleteCreator soft-deletes a creator
+func DeleteCreator(c *gin.Context) {
+	creatorID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return "", "", "", err
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+		return
 	}
-	defer resp.Body.Close()
 
-	var detailsResult struct {
-		Items []struct {
-			Snippet struct {
-				Title       string `json:"title"`
-				Description string `json:"description"`
-			} `json:"snippet"`
-		} `json:"items"`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := db.DeleteCreator(ctx, creatorID); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Creator not found"})
+			return
+		}
+		logger.Log.Error("Failed to delete creator", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete creator"})
+		return
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&detailsResult); err != nil {
-		return "", "", "", err
+	c.JSON(http.StatusOK, gin.H{"message": "Creator deleted"})
+}
+
+// RestoreCreator brings back a soft-deleted creator
+func RestoreCreator(c *gin.Context) {
+	creatorID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+		return
 	}
 
-	if len(detailsResult.Items) == 0 {
-		return "", "", "", fmt.Errorf("failed to fetch details for channel ID: %s", channelID)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	creator, err := db.RestoreCreator(ctx, creatorID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No deleted creator to restore, merged creators cannot be restored"})
+			return
+		}
+		logger.Log.Error("Failed to restore creator", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore creator"})
+		return
+	}
+
+	c.JSON(http.StatusOK, creator)
+}
+
+// MergeCreators moves the tags and votes of a duplicate creator into the
+// creator in the path and soft-deletes the duplicate
+func MergeCreators(c *gin.Context) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+		return
+	}
+
+	var request struct {
+		DuplicateID int `json:"duplicate_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if request.DuplicateID == targetID {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "A creator cannot be merged into itself"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := db.MergeCreators(ctx, targetID, request.DuplicateID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Creator not found"})
+			return
+		}
+		logger.Log.Error("Failed to merge creators", "target_id", targetID, "duplicate_id", request.DuplicateID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge creators"})
+		return
 	}
 
-	return channelID, detailsResult.Items[0].Snippet.Title, detailsResult.Items[0].Snippet.Description, nil
+	c.JSON(http.StatusOK, result)
 }
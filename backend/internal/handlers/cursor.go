@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// encodeCursor turns a keyset position into an opaque pagination token
+func encodeCursor(position interface{}) string {
+	raw, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor reads a token produced by encodeCursor into position
+func decodeCursor(token string, position interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, position)
+}
+
+// pageLimit reads the limit query parameter, clamped to [1, maxPageSize].
+// It reports false when the parameter is not a number.
+func pageLimit(c *gin.Context) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return defaultPageSize, true
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+	return max(1, min(limit, maxPageSize)), true
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/recommend"
+	"github.com/gin-gonic/gin"
+)
+
+// Diversified results are picked from the top diversityPoolFactor times as
+// many results as asked for, up to maxDiversityPool
+const (
+	diversityPoolFactor = 5
+	maxDiversityPool    = 200
+)
+
+// getTagVectors fetches the tags diversify compares creators by, replaced in
+// tests
+var getTagVectors = db.GetTagVectors
+
+// diversityOptions reads the diversity and max_per_tag parameters. It returns
+// nil when neither asks for re-ranking, and false after responding with 400
+// to invalid values.
+func diversityOptions(c *gin.Context) (*recommend.DiversityOptions, bool) {
+	var opts recommend.DiversityOptions
+
+	if raw := c.Query("diversity"); raw != "" {
+		diversity, err := strconv.ParseFloat(raw, 64)
+		if err != nil || diversity < 0 || diversity > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid diversity, must be between 0 and 1"})
+			return nil, false
+		}
+		opts.Diversity = diversity
+	}
+
+	if raw := c.Query("max_per_tag"); raw != "" {
+		maxPerTag, err := strconv.Atoi(raw)
+		if err != nil || maxPerTag < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_per_tag, must be a positive number"})
+			return nil, false
+		}
+		opts.MaxPerTag = maxPerTag
+	}
+
+	if opts.Diversity == 0 && opts.MaxPerTag == 0 {
+		return nil, true
+	}
+	return &opts, true
+}
+
+// diversityPool is how many top results to re-rank for limit diversified ones
+func diversityPool(limit int) int {
+	return max(limit, min(limit*diversityPoolFactor, maxDiversityPool))
+}
+
+// diversify re-ranks creators for diversity by their tag vectors. It returns
+// the indexes of the first limit creators in their new order.
+func diversify(ctx context.Context, ids []int, relevance []float64, limit int, opts recommend.DiversityOptions) ([]int, error) {
+	vectors, err := getTagVectors(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]recommend.Candidate, len(ids))
+	index := make(map[int]int, len(ids))
+	for i, id := range ids {
+		candidates[i] = recommend.Candidate{ID: id, Relevance: relevance[i], Tags: vectors[id]}
+		index[id] = i
+	}
+
+	picked := recommend.Diversify(candidates, limit, opts)
+	order := make([]int, len(picked))
+	for i, candidate := range picked {
+		order[i] = index[candidate.ID]
+	}
+	return order, nil
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/importer"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize is the largest import file accepted, in bytes
+const maxImportSize = 5 << 20
+
+// ImportCreators starts an asynchronous import from a Takeout subscriptions.csv,
+// an OPML feed list or a JSON array of handles and channel IDs. The file is
+// sent as the "file" field of a multipart form or as the raw request body.
+func ImportCreators(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	data, err := readImportFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request, send a file of at most 5 MB"})
+		return
+	}
+
+	format, inputs, err := importer.Parse(data)
+	if err != nil {
+		if errors.Is(err, importer.ErrEmpty) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No channels found in import"})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	importID, err := db.CreateImport(ctx, userID.(int), format, inputs)
+	if err != nil {
+		logger.Log.Error("Failed to create import", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create import"})
+		return
+	}
+
+	importer.Start(youtube.API, importID)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"id":     importID,
+		"format": format,
+		"status": db.ImportPending,
+		"rows":   len(inputs),
+	})
+}
+
+// readImportFile reads the uploaded file from a multipart form or the raw body
+func readImportFile(c *gin.Context) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return io.ReadAll(file)
+	}
+
+	return io.ReadAll(c.Request.Body)
+}
+
+// GetImport returns the status of an import job and the outcome of each row
+func GetImport(c *gin.Context) {
+	importID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	job, err := db.GetImport(ctx, importID)
+	if err != nil || job.UserID != userID.(int) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
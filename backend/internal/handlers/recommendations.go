@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// explainedRecommendation is a recommendation with a sentence explaining it
+type explainedRecommendation struct {
+	db.Recommendation
+	Explanation string `json:"explanation"`
+}
+
+// GetRecommendations returns creators the logged-in user has not interacted
+// with, ranked against the tags the user upvoted, submitted and follows and
+// against what users with similar likes like
+func GetRecommendations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	diversity, ok := diversityOptions(c)
+	if !ok {
+		return
+	}
+	pool := limit
+	if diversity != nil {
+		pool = diversityPool(limit)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	recommendations, err := db.GetRecommendations(ctx, userID.(int), pool)
+	if err != nil {
+		logger.Log.Error("Failed to fetch recommendations", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recommendations"})
+		return
+	}
+
+	if diversity != nil {
+		ids := make([]int, len(recommendations))
+		relevance := make([]float64, len(recommendations))
+		for i, rec := range recommendations {
+			ids[i], relevance[i] = rec.ID, rec.Score
+		}
+
+		order, err := diversify(ctx, ids, relevance, limit, *diversity)
+		if err != nil {
+			logger.Log.Error("Failed to diversify recommendations", "user_id", userID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recommendations"})
+			return
+		}
+
+		diversified := make([]db.Recommendation, len(order))
+		for i, index := range order {
+			diversified[i] = recommendations[index]
+		}
+		recommendations = diversified
+	}
+
+	explained := make([]explainedRecommendation, len(recommendations))
+	for i, rec := range recommendations {
+		explained[i] = explainedRecommendation{Recommendation: rec, Explanation: explain(rec)}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recommendations": explained})
+}
+
+// explain turns the reason for a recommendation into a sentence
+func explain(rec db.Recommendation) string {
+	reason := rec.Reason
+	if reason == nil {
+		return ""
+	}
+
+	switch reason.Kind {
+	case db.SignalUpvoted:
+		return fmt.Sprintf("Because you upvoted %s on %s", reason.Tag, reason.CreatorName)
+	case db.SignalTagged:
+		return fmt.Sprintf("Because you tagged %s with %s", reason.CreatorName, reason.Tag)
+	case db.SignalFollowed:
+		return fmt.Sprintf("Because you follow %s, which is tagged %s", reason.CreatorName, reason.Tag)
+	case db.SignalCoLiked:
+		return fmt.Sprintf("Because people who like %s also like %s", reason.CreatorName, rec.Name)
+	default:
+		return ""
+	}
+}
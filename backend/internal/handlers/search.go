@@ -2,31 +2,201 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/recommend"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/tagnorm"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/tagquery"
 	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
 
-// SearchCreators finds creators based on a tag
+// SearchCreators finds creators based on a tag, a boolean tag query in q,
+// or free text matched against creator names, descriptions and tags
 func SearchCreators(c *gin.Context) {
-	tag := c.Query("tag")
-	if tag == "" {
+	q, tag, text := c.Query("q"), c.Query("tag"), c.Query("text")
+	modes := 0
+	for _, v := range []string{q, tag, text} {
+		if v != "" {
+			modes++
+		}
+	}
+	switch {
+	case modes > 1:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use only one of q, tag or text"})
+		return
+	case q != "":
+		searchByQuery(c, q)
+		return
+	case text != "":
+		searchByText(c, text)
+		return
+	case tag == "":
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Tag query parameter is required"})
 		return
 	}
 
+	normalized, ok := normalizeTag(c, tag)
+	if !ok {
+		return
+	}
+
+	includeDescendants, err := strconv.ParseBool(c.DefaultQuery("include_descendants", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid include_descendants, must be true or false"})
+		return
+	}
+
+	req, ok := searchOptions(c, db.SearchByTag)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	creators, err := db.SearchCreatorsByTag(ctx, tag)
+	page, err := db.SearchCreatorsByTag(ctx, normalized.Slug, includeDescendants, req.SearchOptions)
 	if err != nil {
 		logger.Log.Error("Failed to search creators", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search creators"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"creators": creators})
+	writeSearchPage(ctx, c, req, page)
+}
+
+// searchByQuery runs a boolean tag query, reporting parse errors with their position
+func searchByQuery(c *gin.Context, q string) {
+	query, err := tagquery.Parse(q)
+	if err != nil {
+		var syntaxErr *tagquery.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": syntaxErr.Error(), "position": syntaxErr.Pos})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query"})
+		return
+	}
+
+	req, ok := searchOptions(c, db.SearchByQuery)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	page, err := db.SearchCreatorsByQuery(ctx, query, req.SearchOptions)
+	if err != nil {
+		logger.Log.Error("Failed to search creators", "query", q, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search creators"})
+		return
+	}
+
+	writeSearchPage(ctx, c, req, page)
+}
+
+// searchByText runs a full-text search blended with matching tags
+func searchByText(c *gin.Context, text string) {
+	if len(text) > tagquery.MaxLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search text is too long"})
+		return
+	}
+
+	// Text that cannot form a tag simply matches no tags
+	slug := tagnorm.Slug(text)
+
+	req, ok := searchOptions(c, db.SearchByText)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	page, err := db.SearchCreatorsByText(ctx, text, slug, req.SearchOptions)
+	if err != nil {
+		logger.Log.Error("Failed to search creators", "text", text, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search creators"})
+		return
+	}
+
+	writeSearchPage(ctx, c, req, page)
+}
+
+// searchRequest holds the paging and re-ranking parameters shared by all search modes
+type searchRequest struct {
+	db.SearchOptions
+	limit     int                         // Results to return; SearchOptions.Limit is the pool size when re-ranking
+	diversity *recommend.DiversityOptions // Set when re-ranking for diversity
+}
+
+// searchOptions reads the limit, cursor and diversity parameters, rejecting
+// cursors from a different search mode. Diversified results come as a single
+// page picked from a larger pool of top results, so they take no cursor.
+func searchOptions(c *gin.Context, mode string) (searchRequest, bool) {
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return searchRequest{}, false
+	}
+	req := searchRequest{SearchOptions: db.SearchOptions{Limit: limit}, limit: limit}
+
+	if req.diversity, ok = diversityOptions(c); !ok {
+		return searchRequest{}, false
+	}
+
+	token := c.Query("cursor")
+	switch {
+	case token != "" && req.diversity != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cursor cannot be combined with diversity"})
+		return searchRequest{}, false
+	case token != "":
+		req.After = &db.SearchCursor{}
+		if err := decodeCursor(token, req.After); err != nil || req.After.Mode != mode {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return searchRequest{}, false
+		}
+	case req.diversity != nil:
+		req.Limit = diversityPool(limit)
+	}
+
+	return req, true
+}
+
+// writeSearchPage responds with a page of search results, re-ranked for
+// diversity when asked for
+func writeSearchPage(ctx context.Context, c *gin.Context, req searchRequest, page db.SearchPage) {
+	if req.diversity != nil {
+		ids := make([]int, len(page.Creators))
+		relevance := make([]float64, len(page.Creators))
+		for i, result := range page.Creators {
+			ids[i], relevance[i] = result.ID, result.Score
+		}
+
+		order, err := diversify(ctx, ids, relevance, req.limit, *req.diversity)
+		if err != nil {
+			logger.Log.Error("Failed to diversify search results", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search creators"})
+			return
+		}
+
+		creators := make([]db.SearchResult, len(order))
+		for i, index := range order {
+			creators[i] = page.Creators[index]
+		}
+		page.Creators, page.Next = creators, nil
+	}
+
+	var nextCursor *string
+	if page.Next != nil {
+		token := encodeCursor(page.Next)
+		nextCursor = &token
+	}
+
+	c.JSON(http.StatusOK, gin.H{"creators": page.Creators, "total": page.Total, "next_cursor": nextCursor})
 }
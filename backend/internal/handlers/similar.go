@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Database calls of GetSimilarCreators, replaced in tests
+var (
+	getCreator         = db.GetCreator
+	getSimilarCreators = db.GetSimilarCreators
+)
+
+// GetSimilarCreators returns the creators whose tags most resemble a creator's,
+// with the tags they share. diversity and max_per_tag re-rank them as they do
+// search results, picking from all stored neighbors up to the diversity pool.
+func GetSimilarCreators(c *gin.Context) {
+	creatorID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+		return
+	}
+
+	// Only the configured number of neighbors is stored per creator
+	limit := 10
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+	}
+	limit = max(1, min(limit, config.AppConfig.Similarity.Neighbors))
+
+	diversity, ok := diversityOptions(c)
+	if !ok {
+		return
+	}
+	pool := limit
+	if diversity != nil {
+		pool = min(diversityPool(limit), config.AppConfig.Similarity.Neighbors)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := getCreator(ctx, creatorID); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Creator not found"})
+			return
+		}
+		logger.Log.Error("Failed to fetch creator", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch similar creators"})
+		return
+	}
+
+	similar, err := getSimilarCreators(ctx, creatorID, pool)
+	if err != nil {
+		logger.Log.Error("Failed to fetch similar creators", "creator_id", creatorID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch similar creators"})
+		return
+	}
+
+	if diversity != nil {
+		ids := make([]int, len(similar))
+		relevance := make([]float64, len(similar))
+		for i, s := range similar {
+			ids[i], relevance[i] = s.ID, s.Score
+		}
+
+		order, err := diversify(ctx, ids, relevance, limit, *diversity)
+		if err != nil {
+			logger.Log.Error("Failed to diversify similar creators", "creator_id", creatorID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch similar creators"})
+			return
+		}
+
+		diversified := make([]db.SimilarCreator, len(order))
+		for i, index := range order {
+			diversified[i] = similar[index]
+		}
+		similar = diversified
+	}
+
+	c.JSON(http.StatusOK, gin.H{"creator_id": creatorID, "similar": similar})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Neighbors of creator 1: two near-identical cooking creators ahead of a
+// travel creator
+var (
+	cookingNeighbors = []db.SimilarCreator{
+		{ID: 2, Name: "Cooking A", Score: 0.9, SharedTags: []string{"cooking"}},
+		{ID: 3, Name: "Cooking B", Score: 0.85, SharedTags: []string{"cooking"}},
+		{ID: 4, Name: "Travel", Score: 0.5, SharedTags: []string{"travel"}},
+	}
+	cookingVectors = map[int]map[int]float64{
+		2: {1: 1},
+		3: {1: 1},
+		4: {2: 1},
+	}
+)
+
+// stubSimilar serves creator 1 and its neighbors without a database,
+// recording the number of neighbors asked for
+func stubSimilar(t *testing.T) *int {
+	t.Helper()
+
+	requested := new(int)
+	oldCreator, oldSimilar, oldVectors := getCreator, getSimilarCreators, getTagVectors
+	oldNeighbors := config.AppConfig.Similarity.Neighbors
+	t.Cleanup(func() {
+		getCreator, getSimilarCreators, getTagVectors = oldCreator, oldSimilar, oldVectors
+		config.AppConfig.Similarity.Neighbors = oldNeighbors
+	})
+
+	config.AppConfig.Similarity.Neighbors = 50
+	getCreator = func(ctx context.Context, id int) (db.Creator, error) {
+		if id != 1 {
+			return db.Creator{}, db.ErrNotFound
+		}
+		return db.Creator{ID: id}, nil
+	}
+	getSimilarCreators = func(ctx context.Context, creatorID, limit int) ([]db.SimilarCreator, error) {
+		*requested = limit
+		return cookingNeighbors[:min(limit, len(cookingNeighbors))], nil
+	}
+	getTagVectors = func(ctx context.Context, creatorIDs []int) (map[int]map[int]float64, error) {
+		vectors := map[int]map[int]float64{}
+		for _, id := range creatorIDs {
+			vectors[id] = cookingVectors[id]
+		}
+		return vectors, nil
+	}
+	return requested
+}
+
+func getSimilar(t *testing.T, target string) (int, []int) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/creators/:id/similar", GetSimilarCreators)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+
+	var body struct {
+		Similar []db.SimilarCreator `json:"similar"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	ids := make([]int, len(body.Similar))
+	for i, s := range body.Similar {
+		ids[i] = s.ID
+	}
+	return w.Code, ids
+}
+
+func TestGetSimilarCreatorsDiversity(t *testing.T) {
+	tests := []struct {
+		name          string
+		target        string
+		wantRequested int
+		want          []int
+	}{
+		{name: "score order", target: "/creators/1/similar?limit=2", wantRequested: 2, want: []int{2, 3}},
+		{
+			name:          "max_per_tag lifts another tag",
+			target:        "/creators/1/similar?limit=2&max_per_tag=1",
+			wantRequested: 10,
+			want:          []int{2, 4},
+		},
+		{
+			name:          "diversity lifts the least similar",
+			target:        "/creators/1/similar?limit=2&diversity=0.6",
+			wantRequested: 10,
+			want:          []int{2, 4},
+		},
+		{
+			name:          "pool is capped at the stored neighbors",
+			target:        "/creators/1/similar?limit=20&diversity=0.5",
+			wantRequested: 50,
+			want:          []int{2, 4, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested := stubSimilar(t)
+
+			code, ids := getSimilar(t, tt.target)
+			if code != http.StatusOK {
+				t.Fatalf("got status %d, want 200", code)
+			}
+			if *requested != tt.wantRequested {
+				t.Errorf("fetched %d neighbors, want %d", *requested, tt.wantRequested)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("got creators %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("got creators %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetSimilarCreatorsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		wantCode int
+	}{
+		{name: "invalid diversity", target: "/creators/1/similar?diversity=2", wantCode: http.StatusBadRequest},
+		{name: "invalid max_per_tag", target: "/creators/1/similar?max_per_tag=0", wantCode: http.StatusBadRequest},
+		{name: "unknown creator", target: "/creators/9/similar?diversity=0.5", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubSimilar(t)
+			if code, _ := getSimilar(t, tt.target); code != tt.wantCode {
+				t.Errorf("got status %d, want %d", code, tt.wantCode)
+			}
+		})
+	}
+}
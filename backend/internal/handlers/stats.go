@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// statsIntervals are the bucket sizes accepted by GetCreatorStats
+var statsIntervals = map[string]bool{"day": true, "week": true, "month": true}
+
+// growthRate describes how a counter changed across a stats range
+type growthRate struct {
+	Change  int64    `json:"change"`
+	Percent *float64 `json:"percent"` // nil when the starting value is zero
+	PerDay  float64  `json:"per_day"`
+}
+
+// statsGrowth holds growth rates for each counter; Subscribers is nil when
+// the subscriber count was hidden for the whole range
+type statsGrowth struct {
+	Subscribers *growthRate `json:"subscribers"`
+	Views       growthRate  `json:"views"`
+	Videos      growthRate  `json:"videos"`
+}
+
+// GetCreatorStats returns a creator's downsampled statistics history and growth rates
+func GetCreatorStats(c *gin.Context) {
+	creatorID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+		return
+	}
+
+	interval := c.DefaultQuery("interval", "day")
+	if !statsIntervals[interval] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval, must be day, week or month"})
+		return
+	}
+
+	to := time.Now()
+	if raw := c.Query("to"); raw != "" {
+		if to, err = parseTimeParam(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to, use RFC 3339 or YYYY-MM-DD"})
+			return
+		}
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if raw := c.Query("from"); raw != "" {
+		if from, err = parseTimeParam(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from, use RFC 3339 or YYYY-MM-DD"})
+			return
+		}
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := db.GetCreator(ctx, creatorID); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Creator not found"})
+			return
+		}
+		logger.Log.Error("Failed to fetch creator", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch creator stats"})
+		return
+	}
+
+	snapshots, err := db.GetCreatorStats(ctx, creatorID, from, to, interval)
+	if err != nil {
+		logger.Log.Error("Failed to fetch creator stats", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch creator stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"creator_id": creatorID,
+		"interval":   interval,
+		"from":       from,
+		"to":         to,
+		"points":     snapshots,
+		"growth":     computeGrowth(snapshots),
+	})
+}
+
+// parseTimeParam accepts an RFC 3339 timestamp or a plain date
+func parseTimeParam(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+// computeGrowth compares the first and last snapshots of a range.
+// It returns nil when there are fewer than two snapshots.
+func computeGrowth(snapshots []db.StatsSnapshot) *statsGrowth {
+	if len(snapshots) < 2 {
+		return nil
+	}
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	growth := &statsGrowth{
+		Views:  newGrowthRate(first.ViewCount, last.ViewCount, first.CapturedAt, last.CapturedAt),
+		Videos: newGrowthRate(first.VideoCount, last.VideoCount, first.CapturedAt, last.CapturedAt),
+	}
+
+	// Subscriber counts can be hidden for part of the range, so compare the
+	// first and last snapshots that have one
+	var firstSubs, lastSubs *db.StatsSnapshot
+	for i := range snapshots {
+		if snapshots[i].SubscriberCount == nil {
+			continue
+		}
+		if firstSubs == nil {
+			firstSubs = &snapshots[i]
+		}
+		lastSubs = &snapshots[i]
+	}
+	if firstSubs != nil && firstSubs != lastSubs {
+		rate := newGrowthRate(*firstSubs.SubscriberCount, *lastSubs.SubscriberCount, firstSubs.CapturedAt, lastSubs.CapturedAt)
+		growth.Subscribers = &rate
+	}
+
+	return growth
+}
+
+func newGrowthRate(start, end int64, startAt, endAt time.Time) growthRate {
+	rate := growthRate{Change: end - start}
+
+	if days := endAt.Sub(startAt).Hours() / 24; days > 0 {
+		rate.PerDay = float64(rate.Change) / days
+	}
+	if start != 0 {
+		percent := float64(rate.Change) / float64(start) * 100
+		rate.Percent = &percent
+	}
+
+	return rate
+}
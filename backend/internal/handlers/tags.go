@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/scoring"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/tagnorm"
 	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
@@ -28,6 +33,11 @@ func AddTag(c *gin.Context) {
 		return
 	}
 
+	tag, ok := normalizeTag(c, request.TagName)
+	if !ok {
+		return
+	}
+
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -39,8 +49,12 @@ func AddTag(c *gin.Context) {
 	defer cancel()
 
 	// Store tag in DB using pgxpool
-	tagID, err := db.AddTag(ctx, creatorID, request.TagName, userID.(int))
+	tagID, tagName, err := db.AddTag(ctx, creatorID, tag.Name, tag.Slug, userID.(int))
 	if err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Tag is already assigned to this creator"})
+			return
+		}
 		logger.Log.Error("Failed to store tag", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store tag"})
 		return
@@ -49,12 +63,20 @@ func AddTag(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{
 		"tag_id":     tagID,
 		"creator_id": creatorID,
-		"tag_name":   request.TagName,
+		"tag_name":   tagName,
 		"user_id":    userID,
 	})
 }
 
-// GetTags fetches all tags for a given creator
+// scoredTag is a creator tag with scores derived from its votes
+type scoredTag struct {
+	db.CreatorTag
+	NetScore   int     `json:"net_score"`
+	Confidence float64 `json:"confidence"` // Wilson lower bound of the upvote share
+}
+
+// GetTags fetches all tags for a given creator, best scored first. Callers
+// with a valid JWT also get their own vote on each tag.
 func GetTags(c *gin.Context) {
 	creatorID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -62,15 +84,115 @@ func GetTags(c *gin.Context) {
 		return
 	}
 
+	// Set by OptionalAuth when the caller is signed in
+	var viewerID *int
+	if userID, exists := c.Get("user_id"); exists {
+		id := userID.(int)
+		viewerID = &id
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	tags, err := db.GetTags(ctx, creatorID)
+	creatorTags, err := db.GetTags(ctx, creatorID, viewerID)
 	if err != nil {
 		logger.Log.Error("Failed to fetch tags", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
 		return
 	}
 
+	tags := make([]scoredTag, len(creatorTags))
+	for i, t := range creatorTags {
+		tags[i] = scoredTag{
+			CreatorTag: t,
+			NetScore:   t.Upvotes - t.Downvotes,
+			Confidence: scoring.Wilson(float64(t.Upvotes), float64(t.Downvotes)),
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, b := tags[i], tags[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if a.NetScore != b.NetScore {
+			return a.NetScore > b.NetScore
+		}
+		return a.CreatorTagID < b.CreatorTagID
+	})
+
 	c.JSON(http.StatusOK, gin.H{"tags": tags})
 }
+
+// SuggestTags autocompletes tag names from existing tags and aliases.
+// With creator_id, tags already on that creator are left out and tags used
+// by similar creators are boosted.
+func SuggestTags(c *gin.Context) {
+	q := c.Query("q")
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q query parameter is required"})
+		return
+	}
+
+	query, ok := normalizeTag(c, q)
+	if !ok {
+		return
+	}
+
+	var creatorID *int
+	if raw := c.Query("creator_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator_id"})
+			return
+		}
+		creatorID = &id
+	}
+
+	limit, ok := suggestLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	suggestions, err := db.SuggestTags(ctx, query.Name, creatorID, limit)
+	if err != nil {
+		logger.Log.Error("Failed to suggest tags", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+}
+
+// suggestLimit parses the limit of a tag suggestion request, clamped to 1..25
+func suggestLimit(c *gin.Context) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 10, true
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+	return max(1, min(limit, 25)), true
+}
+
+// normalizeTag normalizes a submitted tag, responding with 422 when it is
+// empty or too long
+func normalizeTag(c *gin.Context, raw string) (tagnorm.Tag, bool) {
+	tag, err := tagnorm.Normalize(raw)
+	switch {
+	case errors.Is(err, tagnorm.ErrEmpty):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Tag must contain letters or digits"})
+		return tag, false
+	case errors.Is(err, tagnorm.ErrTooLong):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Tag must be at most %d characters", tagnorm.MaxLength)})
+		return tag, false
+	}
+	return tag, true
+}
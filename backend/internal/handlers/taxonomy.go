@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRootTags lists the tags at the top of the hierarchy
+func GetRootTags(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tags, err := db.GetRootTags(ctx)
+	if err != nil {
+		logger.Log.Error("Failed to fetch root tags", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// GetTag returns a tag with its direct parents and children
+func GetTag(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tag, err := db.GetTag(ctx, tagID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+			return
+		}
+		logger.Log.Error("Failed to fetch tag", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+		return
+	}
+
+	parents, err := db.GetTagParents(ctx, tagID)
+	if err != nil {
+		logger.Log.Error("Failed to fetch tag parents", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+		return
+	}
+
+	children, err := db.GetTagChildren(ctx, tagID)
+	if err != nil {
+		logger.Log.Error("Failed to fetch tag children", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag": tag, "parents": parents, "children": children})
+}
+
+// GetTagDescendants lists every tag below a tag with its distance
+func GetTagDescendants(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	descendants, err := db.GetTagDescendants(ctx, tagID)
+	if err != nil {
+		logger.Log.Error("Failed to fetch tag descendants", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag descendants"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag_id": tagID, "descendants": descendants})
+}
+
+// AddTagParent places a tag under a parent tag
+func AddTagParent(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	var request struct {
+		ParentID int `json:"parent_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := db.AddTagParent(ctx, tagID, request.ParentID); err != nil {
+		switch {
+		case errors.Is(err, db.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		case errors.Is(err, db.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Tag already has this parent"})
+		case errors.Is(err, db.ErrCycle):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Parent is the tag itself or one of its descendants"})
+		default:
+			logger.Log.Error("Failed to add tag parent", "tag_id", tagID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add tag parent"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"tag_id": tagID, "parent_id": request.ParentID})
+}
+
+// RemoveTagParent removes a tag from under a parent tag
+func RemoveTagParent(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	parentID, err := strconv.Atoi(c.Param("parent_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent tag ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := db.RemoveTagParent(ctx, tagID, parentID); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag does not have this parent"})
+			return
+		}
+		logger.Log.Error("Failed to remove tag parent", "tag_id", tagID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove tag parent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Parent removed"})
+}
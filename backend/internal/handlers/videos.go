@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// GetVideos lists a creator's uploads, newest first, with cursor pagination
+func GetVideos(c *gin.Context) {
+	creatorID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	var after *db.VideoCursor
+	if token := c.Query("cursor"); token != "" {
+		after = &db.VideoCursor{}
+		if err := decodeCursor(token, after); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	videos, next, err := db.ListVideos(ctx, creatorID, after, limit)
+	if err != nil {
+		logger.Log.Error("Failed to fetch videos", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch videos"})
+		return
+	}
+
+	var nextCursor *string
+	if next != nil {
+		token := encodeCursor(next)
+		nextCursor = &token
+	}
+
+	c.JSON(http.StatusOK, gin.H{"videos": videos, "next_cursor": nextCursor})
+}
@@ -0,0 +1,188 @@
+// Package importer bulk-imports creators from subscription exports.
+package importer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/ingest"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+)
+
+// Start processes an import job in the background
+func Start(client youtube.Client, importID int) {
+	go func() {
+		if err := Run(context.Background(), client, importID); err != nil {
+			logger.Log.Error("Import failed", "import_id", importID, "error", err)
+		}
+	}()
+}
+
+// Resume restarts jobs that were pending or interrupted by a restart
+func Resume(ctx context.Context, client youtube.Client) error {
+	ids, err := db.UnfinishedImports(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		logger.Log.Info("Resuming import", "import_id", id)
+		Start(client, id)
+	}
+	return nil
+}
+
+// Run processes the pending rows of an import job. Channel IDs are looked up
+// in batches; handles and URLs are resolved one at a time. Every channel found
+// goes through the regular creator upsert.
+func Run(ctx context.Context, client youtube.Client, importID int) error {
+	if err := db.SetImportStatus(ctx, importID, db.ImportRunning); err != nil {
+		return err
+	}
+
+	rows, err := db.PendingImportRows(ctx, importID)
+	if err != nil {
+		return err
+	}
+
+	j := &job{ctx: ctx, client: client}
+
+	var byID []db.ImportRow
+	for _, row := range rows {
+		ref, err := youtube.ParseChannelRef(row.Input)
+		if err != nil {
+			j.finish(row, db.RowFailed, nil, "Unrecognized channel reference")
+			continue
+		}
+		if ref.Kind == youtube.RefChannelID {
+			row.Input = ref.Value
+			byID = append(byID, row)
+			continue
+		}
+		j.resolve(row, ref)
+	}
+
+	for start := 0; start < len(byID); start += youtube.MaxBatchSize {
+		j.fetchBatch(byID[start:min(start+youtube.MaxBatchSize, len(byID))])
+	}
+
+	status := db.ImportCompleted
+	if j.quotaExceeded {
+		status = db.ImportFailed
+	}
+	logger.Log.Info("Import finished", "import_id", importID, "status", status)
+	return db.SetImportStatus(ctx, importID, status)
+}
+
+// job holds the state of one import run
+type job struct {
+	ctx           context.Context
+	client        youtube.Client
+	quotaExceeded bool
+}
+
+// resolve looks up a single handle or URL row
+func (j *job) resolve(row db.ImportRow, ref youtube.ChannelRef) {
+	if j.quotaExceeded {
+		j.finish(row, db.RowFailed, nil, "YouTube API quota exhausted")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(j.ctx, 10*time.Second)
+	defer cancel()
+
+	channel, err := j.client.ResolveChannel(ctx, ref)
+	switch {
+	case err == nil:
+		j.store(row, channel)
+	case errors.Is(err, youtube.ErrNotFound):
+		j.finish(row, db.RowNotFound, nil, "")
+	case errors.Is(err, youtube.ErrAmbiguous):
+		j.finish(row, db.RowFailed, nil, "Matches more than one channel")
+	default:
+		j.fail(row, err)
+	}
+}
+
+// fetchBatch looks up up to youtube.MaxBatchSize channel ID rows in one call
+func (j *job) fetchBatch(rows []db.ImportRow) {
+	if j.quotaExceeded {
+		for _, row := range rows {
+			j.finish(row, db.RowFailed, nil, "YouTube API quota exhausted")
+		}
+		return
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.Input
+	}
+
+	ctx, cancel := context.WithTimeout(j.ctx, 10*time.Second)
+	defer cancel()
+
+	channels, err := j.client.FetchChannels(ctx, ids)
+	if err != nil {
+		for _, row := range rows {
+			j.fail(row, err)
+		}
+		return
+	}
+
+	found := make(map[string]*youtube.Channel, len(channels))
+	for i := range channels {
+		found[channels[i].ID] = &channels[i]
+	}
+
+	for _, row := range rows {
+		if channel, ok := found[row.Input]; ok {
+			j.store(row, channel)
+		} else {
+			j.finish(row, db.RowNotFound, nil, "")
+		}
+	}
+}
+
+// store upserts a found channel as a creator
+func (j *job) store(row db.ImportRow, channel *youtube.Channel) {
+	creator, created, err := ingest.AddChannel(j.ctx, channel)
+	if err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			j.finish(row, db.RowFailed, nil, "Handle is already used by another creator")
+			return
+		}
+		j.fail(row, err)
+		return
+	}
+	if creator.DeletedAt != nil {
+		j.finish(row, db.RowFailed, &creator.ID, "Creator was removed by a moderator")
+		return
+	}
+
+	status := db.RowExisting
+	if created {
+		status = db.RowAdded
+	}
+	j.finish(row, status, &creator.ID, "")
+}
+
+// fail marks a row failed because of an unexpected error
+func (j *job) fail(row db.ImportRow, err error) {
+	if errors.Is(err, youtube.ErrQuotaExceeded) {
+		j.quotaExceeded = true
+		j.finish(row, db.RowFailed, nil, "YouTube API quota exhausted")
+		return
+	}
+
+	logger.Log.Error("Failed to import row", "row", row.RowNumber, "error", err)
+	j.finish(row, db.RowFailed, nil, "Failed to import channel")
+}
+
+func (j *job) finish(row db.ImportRow, status string, creatorID *int, errMsg string) {
+	if err := db.UpdateImportRow(j.ctx, row.ID, status, creatorID, errMsg); err != nil {
+		logger.Log.Error("Failed to update import row", "row", row.RowNumber, "error", err)
+	}
+}
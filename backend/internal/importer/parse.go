@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Import formats
+const (
+	FormatCSV  = "csv"
+	FormatOPML = "opml"
+	FormatJSON = "json"
+)
+
+// MaxRows is the largest number of channels accepted in one import
+const MaxRows = 5000
+
+// ErrEmpty is returned when an import file contains no channels
+var ErrEmpty = errors.New("import contains no channels")
+
+// Parse detects the format of an import file and returns one channel
+// reference per row: a channel ID, @handle or channel URL.
+func Parse(data []byte) (string, []string, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))) // Strip UTF-8 BOM
+
+	var format string
+	var inputs []string
+	var err error
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		format = FormatJSON
+		inputs, err = parseJSON(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		format = FormatOPML
+		inputs, err = parseOPML(trimmed)
+	default:
+		format = FormatCSV
+		inputs, err = parseTakeoutCSV(trimmed)
+	}
+	if err != nil {
+		return format, nil, err
+	}
+
+	if len(inputs) == 0 {
+		return format, nil, ErrEmpty
+	}
+	if len(inputs) > MaxRows {
+		return format, nil, fmt.Errorf("import has %d channels, maximum is %d", len(inputs), MaxRows)
+	}
+
+	return format, inputs, nil
+}
+
+// parseJSON reads an array of handles, channel IDs or channel URLs
+func parseJSON(data []byte) ([]string, error) {
+	var entries []string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid JSON, expected an array of strings: %w", err)
+	}
+
+	var inputs []string
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			inputs = append(inputs, entry)
+		}
+	}
+	return inputs, nil
+}
+
+// parseTakeoutCSV reads a Google Takeout subscriptions.csv, whose columns are
+// channel ID, channel URL and channel title. The header row is localized, so
+// it is recognized by not containing a channel ID rather than by its text.
+func parseTakeoutCSV(data []byte) ([]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	var inputs []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		if input := csvChannel(record); input != "" {
+			inputs = append(inputs, input)
+		}
+	}
+	return inputs, nil
+}
+
+// csvChannel picks the channel ID column of a row, falling back to the URL column
+func csvChannel(record []string) string {
+	if len(record) == 0 {
+		return ""
+	}
+
+	id := strings.TrimSpace(record[0])
+	if strings.HasPrefix(id, "UC") {
+		return id
+	}
+	if len(record) > 1 {
+		if u := strings.TrimSpace(record[1]); strings.Contains(u, "youtube.com/") {
+			return u
+		}
+	}
+	return ""
+}
+
+// opmlOutline is an outline element; feed lists nest them inside folders
+type opmlOutline struct {
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	HTMLURL  string        `xml:"htmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// parseOPML reads a feed reader export of YouTube channel feeds
+// (https://www.youtube.com/feeds/videos.xml?channel_id=UC...)
+func parseOPML(data []byte) ([]string, error) {
+	var doc struct {
+		Body struct {
+			Outlines []opmlOutline `xml:"outline"`
+		} `xml:"body"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OPML: %w", err)
+	}
+
+	var inputs []string
+	var walk func([]opmlOutline)
+	walk = func(outlines []opmlOutline) {
+		for _, o := range outlines {
+			if input := opmlChannel(o); input != "" {
+				inputs = append(inputs, input)
+			}
+			walk(o.Outlines)
+		}
+	}
+	walk(doc.Body.Outlines)
+
+	return inputs, nil
+}
+
+// opmlChannel extracts the channel from a feed URL, falling back to the page URL
+func opmlChannel(o opmlOutline) string {
+	if u, err := url.Parse(o.XMLURL); err == nil {
+		if id := u.Query().Get("channel_id"); id != "" {
+			return id
+		}
+	}
+	if strings.Contains(o.HTMLURL, "youtube.com/") {
+		return o.HTMLURL
+	}
+	return ""
+}
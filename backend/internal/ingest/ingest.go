@@ -0,0 +1,115 @@
+// Package ingest stores channel data fetched from YouTube.
+package ingest
+
+import (
+	"context"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+)
+
+const (
+	// initialUploads is how many recent uploads are pulled for a new creator
+	initialUploads = 50
+	// maxUploadsPerSync caps an incremental sync of a very active channel
+	maxUploadsPerSync = 200
+)
+
+// AddChannel upserts a creator from a fetched channel and records a
+// statistics snapshot. The returned bool reports whether the creator is new.
+func AddChannel(ctx context.Context, channel *youtube.Channel) (db.Creator, bool, error) {
+	creator, created, err := db.AddCreator(ctx, creatorFromChannel(channel))
+	if err != nil {
+		return db.Creator{}, false, err
+	}
+
+	recordStats(ctx, creator.ID, channel)
+	return creator, created, nil
+}
+
+// RefreshChannel updates an existing creator from a fetched channel, records
+// a statistics snapshot and returns the creator's ID
+func RefreshChannel(ctx context.Context, channel *youtube.Channel) (int, error) {
+	creatorID, err := db.RefreshCreator(ctx, creatorFromChannel(channel))
+	if err != nil {
+		return 0, err
+	}
+
+	recordStats(ctx, creatorID, channel)
+	return creatorID, nil
+}
+
+// SyncUploads stores uploads published after the creator's newest stored video.
+// A creator without stored videos gets its initialUploads most recent uploads.
+// It returns the number of videos fetched.
+func SyncUploads(ctx context.Context, client youtube.Client, creatorID int, uploadsPlaylistID string) (int, error) {
+	if uploadsPlaylistID == "" {
+		return 0, nil
+	}
+
+	since, err := db.LatestVideoPublishedAt(ctx, creatorID)
+	if err != nil {
+		return 0, err
+	}
+
+	limit := maxUploadsPerSync
+	if since.IsZero() {
+		limit = initialUploads
+	}
+
+	fetched, err := client.FetchVideos(ctx, uploadsPlaylistID, since, limit)
+	if err != nil {
+		return 0, err
+	}
+	if len(fetched) == 0 {
+		return 0, nil
+	}
+
+	videos := make([]db.Video, len(fetched))
+	for i, v := range fetched {
+		videos[i] = db.Video{
+			YouTubeVideoID:  v.ID,
+			Title:           v.Title,
+			PublishedAt:     v.PublishedAt,
+			DurationSeconds: int(v.Duration.Seconds()),
+			ThumbnailURL:    v.ThumbnailURL,
+		}
+	}
+
+	if err := db.UpsertVideos(ctx, creatorID, videos); err != nil {
+		return 0, err
+	}
+
+	return len(videos), nil
+}
+
+func creatorFromChannel(channel *youtube.Channel) db.Creator {
+	return db.Creator{
+		YouTubeID:         channel.ID,
+		YouTubeHandle:     channel.Handle,
+		Name:              channel.Title,
+		Description:       channel.Description,
+		ThumbnailURL:      channel.ThumbnailURL,
+		BannerURL:         channel.BannerURL,
+		Country:           channel.Country,
+		Keywords:          channel.Keywords,
+		UploadsPlaylistID: channel.UploadsPlaylistID,
+	}
+}
+
+// recordStats stores a statistics snapshot. Failures are logged rather than
+// returned since the creator itself was stored.
+func recordStats(ctx context.Context, creatorID int, channel *youtube.Channel) {
+	stats := db.StatsSnapshot{
+		ViewCount:  channel.Statistics.ViewCount,
+		VideoCount: channel.Statistics.VideoCount,
+	}
+	if !channel.Statistics.HiddenSubscriberCount {
+		stats.SubscriberCount = &channel.Statistics.SubscriberCount
+	}
+
+	if err := db.RecordCreatorStats(ctx, creatorID, stats); err != nil {
+		logger.Log.Error("Failed to record creator stats", "creator_id", creatorID, "error", err)
+	}
+}
@@ -0,0 +1,44 @@
+package ingest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube/youtubetest"
+)
+
+// TestCreatorFromResolvedChannel runs the API half of adding a creator, from
+// user input to the row that would be stored, against the fake server
+func TestCreatorFromResolvedChannel(t *testing.T) {
+	server := youtubetest.NewServer()
+	defer server.Close()
+	client := youtube.NewClient(youtubetest.Config(server), nil)
+
+	ref, err := youtube.ParseChannelRef("https://www.youtube.com/@veritasium")
+	if err != nil {
+		t.Fatalf("ParseChannelRef: %v", err)
+	}
+	channel, err := client.ResolveChannel(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("ResolveChannel: %v", err)
+	}
+
+	got := creatorFromChannel(channel)
+	want := db.Creator{
+		YouTubeID:         "UCHnyfMqiRRG1u-2MsSQLbXA",
+		YouTubeHandle:     "@veritasium",
+		Name:              "Veritasium",
+		Description:       "An element of truth - videos about science, education, and anything else I find interesting.",
+		ThumbnailURL:      "https://yt3.ggpht.com/veritasium-high=s800-c-k-c0x00ffffff-no-rj",
+		BannerURL:         "https://yt3.googleusercontent.com/veritasium-banner",
+		Country:           "US",
+		Keywords:          "science physics education engineering",
+		UploadsPlaylistID: "UUHnyfMqiRRG1u-2MsSQLbXA",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package recommend
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Weight of each kind of activity in a user's taste profile. Followed
+// creators contribute all their tags, scaled by each tag's weight on them.
+const (
+	UpvoteSignalWeight = 1.0
+	TagSignalWeight    = 0.5
+	FollowSignalWeight = 0.25
+)
+
+// Kinds of events
+const (
+	EventTagged   = "tagged"
+	EventUpvote   = "upvote"
+	EventDownvote = "downvote"
+)
+
+// Event is a user submitting a tag on a creator or voting on one
+type Event struct {
+	UserID    int       `json:"user_id"`
+	CreatorID int       `json:"creator_id"`
+	TagID     int       `json:"tag_id"`
+	Kind      string    `json:"kind"`
+	At        time.Time `json:"at"`
+}
+
+// Dataset is what strategies learn from, derived from events the same way
+// the database derives it from votes and creator tags
+type Dataset struct {
+	Interactions []Interaction           // Upvotes and tag submissions per user and creator
+	TagVectors   map[int]map[int]float64 // IDF-weighted tag vector of each creator
+	IDF          map[int]float64         // Inverse document frequency of each tag
+	Profiles     map[int]map[int]float64 // Tag signal weights of each user, before IDF
+	Seen         map[int]map[int]bool    // Creators each user interacted with in any way
+	Popularity   map[int]int             // Users who interacted with each creator
+	Users        int                     // Users with at least one interaction
+}
+
+// BuildDataset derives a dataset from events
+func BuildDataset(events []Event) *Dataset {
+	type creatorTag struct{ creatorID, tagID int }
+	type userCreator struct{ userID, creatorID int }
+
+	tagged := map[creatorTag]bool{}
+	net := map[creatorTag]int{}
+	counts := map[userCreator]int{}
+	ds := &Dataset{
+		TagVectors: map[int]map[int]float64{},
+		IDF:        map[int]float64{},
+		Profiles:   map[int]map[int]float64{},
+		Seen:       map[int]map[int]bool{},
+		Popularity: map[int]int{},
+	}
+
+	for _, e := range events {
+		key := creatorTag{e.CreatorID, e.TagID}
+		signal := 0.0
+		switch e.Kind {
+		case EventTagged:
+			tagged[key] = true
+			counts[userCreator{e.UserID, e.CreatorID}]++
+			signal = TagSignalWeight
+		case EventUpvote:
+			net[key]++
+			counts[userCreator{e.UserID, e.CreatorID}]++
+			signal = UpvoteSignalWeight
+		case EventDownvote:
+			net[key]--
+		}
+
+		if ds.Seen[e.UserID] == nil {
+			ds.Seen[e.UserID] = map[int]bool{}
+		}
+		ds.Seen[e.UserID][e.CreatorID] = true
+
+		if signal > 0 {
+			if ds.Profiles[e.UserID] == nil {
+				ds.Profiles[e.UserID] = map[int]float64{}
+			}
+			ds.Profiles[e.UserID][e.TagID] += signal
+		}
+	}
+
+	// Submitting a tag counts as one upvote, as in creator_tag_weights
+	df := map[int]int{}
+	for key := range tagged {
+		weight := math.Log(1 + math.Max(float64(1+net[key]), 0))
+		if weight <= 0 {
+			continue
+		}
+		if ds.TagVectors[key.creatorID] == nil {
+			ds.TagVectors[key.creatorID] = map[int]float64{}
+		}
+		ds.TagVectors[key.creatorID][key.tagID] = weight
+		df[key.tagID]++
+	}
+
+	for tagID, n := range df {
+		ds.IDF[tagID] = math.Log(1 + float64(len(ds.TagVectors))/float64(n))
+	}
+	for _, vector := range ds.TagVectors {
+		for tagID := range vector {
+			vector[tagID] *= ds.IDF[tagID]
+		}
+	}
+
+	users := map[int]bool{}
+	for key, n := range counts {
+		ds.Interactions = append(ds.Interactions, Interaction{
+			UserID:    key.userID,
+			CreatorID: key.creatorID,
+			Weight:    math.Log(1 + float64(n)),
+		})
+		ds.Popularity[key.creatorID]++
+		users[key.userID] = true
+	}
+	ds.Users = len(users)
+
+	// Keep runs on the same events reproducible despite map ordering
+	sort.Slice(ds.Interactions, func(i, j int) bool {
+		a, b := ds.Interactions[i], ds.Interactions[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.CreatorID < b.CreatorID
+	})
+
+	return ds
+}
@@ -0,0 +1,121 @@
+package recommend
+
+import "math"
+
+// Candidate is a result to re-rank: its relevance, in any non-negative unit,
+// and its tag vector keyed by tag ID
+type Candidate struct {
+	ID        int
+	Relevance float64
+	Tags      map[int]float64
+}
+
+// DiversityOptions controls Diversify
+type DiversityOptions struct {
+	Diversity float64 // From 0, relevance order, to 1, each pick least like those before it
+	MaxPerTag int     // Results that may share a dominant tag; 0 for no cap
+}
+
+// Diversify picks up to limit candidates by maximal marginal relevance: each
+// pick maximizes (1-d) * relevance - d * similarity to the closest earlier
+// pick, with relevance scaled to [0, 1] and similarity the cosine of tag
+// vectors. Candidates whose dominant tag, their highest weighted, already
+// has MaxPerTag picks are only used once no other candidates are left.
+// Ties go to the earlier candidate, so with Diversity 0 and no cap the
+// input order is kept.
+func Diversify(candidates []Candidate, limit int, opts DiversityOptions) []Candidate {
+	d := math.Max(0, math.Min(opts.Diversity, 1))
+
+	maxRelevance := 0.0
+	norms := make([]float64, len(candidates))
+	dominant := make([]int, len(candidates))
+	for i, c := range candidates {
+		maxRelevance = math.Max(maxRelevance, c.Relevance)
+		norms[i] = norm(c.Tags)
+		dominant[i] = dominantTag(c.Tags)
+	}
+
+	// Highest similarity of each candidate to any pick so far
+	closest := make([]float64, len(candidates))
+	picked := make([]bool, len(candidates))
+	perTag := map[int]int{}
+
+	var result []Candidate
+	for len(result) < min(limit, len(candidates)) {
+		best, capped := -1, -1
+		var bestScore, cappedScore float64
+		for i, c := range candidates {
+			if picked[i] {
+				continue
+			}
+			relevance := c.Relevance
+			if maxRelevance > 0 {
+				relevance /= maxRelevance
+			}
+			score := (1-d)*relevance - d*closest[i]
+
+			if opts.MaxPerTag > 0 && dominant[i] != 0 && perTag[dominant[i]] >= opts.MaxPerTag {
+				if capped == -1 || score > cappedScore {
+					capped, cappedScore = i, score
+				}
+				continue
+			}
+			if best == -1 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best == -1 {
+			best = capped
+		}
+
+		picked[best] = true
+		perTag[dominant[best]]++
+		result = append(result, candidates[best])
+
+		for i, c := range candidates {
+			if !picked[i] {
+				closest[i] = math.Max(closest[i], cosine(c.Tags, candidates[best].Tags, norms[i], norms[best]))
+			}
+		}
+	}
+
+	return result
+}
+
+// dominantTag returns the highest weighted tag, the lowest ID on ties, or 0
+// when there are no positively weighted tags
+func dominantTag(tags map[int]float64) int {
+	dominant, weight := 0, 0.0
+	for _, tagID := range sortedKeys(tags) {
+		if tags[tagID] > weight {
+			dominant, weight = tagID, tags[tagID]
+		}
+	}
+	return dominant
+}
+
+// norm returns the length of a tag vector, summing in tag order so that
+// results are reproducible
+func norm(tags map[int]float64) float64 {
+	sum := 0.0
+	for _, tagID := range sortedKeys(tags) {
+		sum += tags[tagID] * tags[tagID]
+	}
+	return math.Sqrt(sum)
+}
+
+// cosine returns the cosine similarity of two tag vectors with norms na and
+// nb, summing in tag order like norm
+func cosine(a, b map[int]float64, na, nb float64) float64 {
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	dot := 0.0
+	for _, tagID := range sortedKeys(a) {
+		dot += a[tagID] * b[tagID]
+	}
+	return dot / (na * nb)
+}
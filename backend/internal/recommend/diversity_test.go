@@ -0,0 +1,183 @@
+package recommend
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tag IDs used by the fixtures
+const (
+	tagCooking = iota + 1
+	tagVegan
+	tagBaking
+	tagTravel
+	tagMusic
+)
+
+// cookingCluster is three near-identical cooking creators ranked above a
+// travel creator and a music creator
+var cookingCluster = []Candidate{
+	{ID: 1, Relevance: 0.95, Tags: map[int]float64{tagCooking: 1, tagVegan: 0.5}},
+	{ID: 2, Relevance: 0.93, Tags: map[int]float64{tagCooking: 1, tagVegan: 0.4}},
+	{ID: 3, Relevance: 0.90, Tags: map[int]float64{tagCooking: 1, tagVegan: 0.6, tagBaking: 0.1}},
+	{ID: 4, Relevance: 0.70, Tags: map[int]float64{tagTravel: 1, tagCooking: 0.2}},
+	{ID: 5, Relevance: 0.60, Tags: map[int]float64{tagMusic: 1}},
+}
+
+func ids(candidates []Candidate) []int {
+	out := make([]int, len(candidates))
+	for i, c := range candidates {
+		out[i] = c.ID
+	}
+	return out
+}
+
+func TestDiversify(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []Candidate
+		limit      int
+		opts       DiversityOptions
+		want       []int
+	}{
+		{
+			name:       "no diversity keeps relevance order",
+			candidates: cookingCluster,
+			limit:      5,
+			opts:       DiversityOptions{},
+			want:       []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:       "diversity lifts creators unlike the top result",
+			candidates: cookingCluster,
+			limit:      5,
+			opts:       DiversityOptions{Diversity: 0.5},
+			want:       []int{1, 5, 4, 2, 3},
+		},
+		{
+			name:       "less diversity still prefers the more relevant outsider",
+			candidates: cookingCluster,
+			limit:      5,
+			opts:       DiversityOptions{Diversity: 0.3},
+			want:       []int{1, 4, 5, 2, 3},
+		},
+		{
+			name:       "full diversity ignores relevance after the first pick",
+			candidates: cookingCluster,
+			limit:      3,
+			opts:       DiversityOptions{Diversity: 1},
+			want:       []int{1, 5, 4},
+		},
+		{
+			name:       "limit truncates",
+			candidates: cookingCluster,
+			limit:      2,
+			opts:       DiversityOptions{},
+			want:       []int{1, 2},
+		},
+		{
+			name:       "cap defers creators sharing a dominant tag",
+			candidates: cookingCluster,
+			limit:      5,
+			opts:       DiversityOptions{MaxPerTag: 1},
+			want:       []int{1, 4, 5, 2, 3},
+		},
+		{
+			name:       "cap with a short list keeps the clustered creators first",
+			candidates: cookingCluster,
+			limit:      3,
+			opts:       DiversityOptions{MaxPerTag: 2},
+			want:       []int{1, 2, 4},
+		},
+		{
+			name: "creators without tags are never capped",
+			candidates: []Candidate{
+				{ID: 1, Relevance: 3},
+				{ID: 2, Relevance: 2},
+				{ID: 3, Relevance: 1, Tags: map[int]float64{tagMusic: 1}},
+			},
+			limit: 3,
+			opts:  DiversityOptions{MaxPerTag: 1},
+			want:  []int{1, 2, 3},
+		},
+		{
+			name: "ties keep input order",
+			candidates: []Candidate{
+				{ID: 7, Relevance: 1, Tags: map[int]float64{tagTravel: 1}},
+				{ID: 3, Relevance: 1, Tags: map[int]float64{tagMusic: 1}},
+				{ID: 5, Relevance: 1, Tags: map[int]float64{tagBaking: 1}},
+			},
+			limit: 3,
+			opts:  DiversityOptions{Diversity: 0.5},
+			want:  []int{7, 3, 5},
+		},
+		{
+			name:       "empty input",
+			candidates: nil,
+			limit:      10,
+			opts:       DiversityOptions{Diversity: 0.5, MaxPerTag: 1},
+			want:       []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(Diversify(tt.candidates, tt.limit, tt.opts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diversify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiversifyCapsDominantTag(t *testing.T) {
+	// Ten creators dominated by cooking, ranked above five others
+	var candidates []Candidate
+	for i := 1; i <= 10; i++ {
+		candidates = append(candidates, Candidate{
+			ID:        i,
+			Relevance: 2 - float64(i)/100,
+			Tags:      map[int]float64{tagCooking: 1, tagVegan: float64(i) / 20},
+		})
+	}
+	for i := 11; i <= 15; i++ {
+		candidates = append(candidates, Candidate{
+			ID:        i,
+			Relevance: 1 - float64(i)/100,
+			Tags:      map[int]float64{i: 1},
+		})
+	}
+
+	got := Diversify(candidates, 6, DiversityOptions{Diversity: 0.3, MaxPerTag: 2})
+
+	cooking := 0
+	for _, c := range got {
+		if dominantTag(c.Tags) == tagCooking {
+			cooking++
+		}
+	}
+	if cooking != 2 {
+		t.Errorf("got %d cooking creators in %v, want 2", cooking, ids(got))
+	}
+	if got[0].ID != 1 {
+		t.Errorf("first result = %d, want the most relevant creator 1", got[0].ID)
+	}
+}
+
+func TestDominantTag(t *testing.T) {
+	tests := []struct {
+		tags map[int]float64
+		want int
+	}{
+		{map[int]float64{tagCooking: 0.5, tagTravel: 0.9}, tagTravel},
+		{map[int]float64{tagTravel: 1, tagVegan: 1}, tagVegan},
+		{map[int]float64{tagMusic: 0}, 0},
+		{nil, 0},
+	}
+
+	for _, tt := range tests {
+		if got := dominantTag(tt.tags); got != tt.want {
+			t.Errorf("dominantTag(%v) = %d, want %d", tt.tags, got, tt.want)
+		}
+	}
+}
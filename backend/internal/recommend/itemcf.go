@@ -0,0 +1,119 @@
+// Package recommend implements recommendation algorithms over in-memory
+// interaction data, for offline jobs that cannot afford them per request.
+package recommend
+
+import (
+	"math"
+	"sort"
+)
+
+// Interaction is a user's positive interaction with a creator
+type Interaction struct {
+	UserID    int
+	CreatorID int
+	Weight    float64
+}
+
+// Neighbor is a creator similar to another, with the number of users who
+// interacted with both
+type Neighbor struct {
+	CreatorID int
+	Score     float64
+	Support   int
+}
+
+// ItemCFOptions controls ItemSimilarities
+type ItemCFOptions struct {
+	Neighbors       int     // Neighbors kept per creator
+	Shrinkage       float64 // Pulls similarities with little support towards 0
+	MinCoOccurrence int     // Users two creators need in common to be compared
+}
+
+// ItemSimilarities computes item-based collaborative filtering neighbors: the
+// cosine similarity of creators' interaction vectors across users, scaled by
+// support / (support + Shrinkage) so that pairs few users share count less.
+// Each creator keeps its opts.Neighbors most similar creators, best first
+// with ties broken by creator ID.
+func ItemSimilarities(interactions []Interaction, opts ItemCFOptions) map[int][]Neighbor {
+	type entry struct {
+		creatorID int
+		weight    float64
+	}
+	type pair struct{ a, b int }
+	type cooccurrence struct {
+		dot     float64
+		support int
+	}
+
+	byUser := map[int][]entry{}
+	norms := map[int]float64{}
+	for _, in := range interactions {
+		if in.Weight <= 0 {
+			continue
+		}
+		byUser[in.UserID] = append(byUser[in.UserID], entry{in.CreatorID, in.Weight})
+		norms[in.CreatorID] += in.Weight * in.Weight
+	}
+
+	// Visit users in a fixed order so sums, and so ties, are reproducible
+	users := make([]int, 0, len(byUser))
+	for userID := range byUser {
+		users = append(users, userID)
+	}
+	sort.Ints(users)
+
+	pairs := map[pair]*cooccurrence{}
+	for _, userID := range users {
+		entries := byUser[userID]
+		for i := range entries {
+			for j := i + 1; j < len(entries); j++ {
+				a, b := entries[i], entries[j]
+				if a.creatorID == b.creatorID {
+					continue
+				}
+				if a.creatorID > b.creatorID {
+					a, b = b, a
+				}
+				key := pair{a.creatorID, b.creatorID}
+				co := pairs[key]
+				if co == nil {
+					co = &cooccurrence{}
+					pairs[key] = co
+				}
+				co.dot += a.weight * b.weight
+				co.support++
+			}
+		}
+	}
+
+	neighbors := map[int][]Neighbor{}
+	for key, co := range pairs {
+		if co.support < opts.MinCoOccurrence {
+			continue
+		}
+		cosine := co.dot / math.Sqrt(norms[key.a]*norms[key.b])
+		score := cosine * float64(co.support) / (float64(co.support) + opts.Shrinkage)
+		neighbors[key.a] = append(neighbors[key.a], Neighbor{CreatorID: key.b, Score: score, Support: co.support})
+		neighbors[key.b] = append(neighbors[key.b], Neighbor{CreatorID: key.a, Score: score, Support: co.support})
+	}
+
+	for creatorID, list := range neighbors {
+		sortNeighbors(list)
+		if len(list) > opts.Neighbors {
+			list = list[:opts.Neighbors]
+		}
+		neighbors[creatorID] = list
+	}
+
+	return neighbors
+}
+
+// sortNeighbors orders neighbors by score, best first, then by creator ID
+func sortNeighbors(list []Neighbor) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].CreatorID < list[j].CreatorID
+	})
+}
@@ -0,0 +1,187 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Creator IDs used by the item CF fixtures
+const (
+	creatorA = iota + 1
+	creatorB
+	creatorC
+	creatorD
+)
+
+// coLiked has four users, each interacting once with weight 1, so a
+// creator's squared norm is its user count: A 4, B 3, C 2 and D 1.
+// A and B share 3 users, A and C 2, B and C 2, and A and D 1.
+var coLiked = []Interaction{
+	{UserID: 1, CreatorID: creatorA, Weight: 1},
+	{UserID: 1, CreatorID: creatorB, Weight: 1},
+	{UserID: 1, CreatorID: creatorC, Weight: 1},
+	{UserID: 2, CreatorID: creatorA, Weight: 1},
+	{UserID: 2, CreatorID: creatorB, Weight: 1},
+	{UserID: 3, CreatorID: creatorA, Weight: 1},
+	{UserID: 3, CreatorID: creatorB, Weight: 1},
+	{UserID: 3, CreatorID: creatorC, Weight: 1},
+	{UserID: 4, CreatorID: creatorA, Weight: 1},
+	{UserID: 4, CreatorID: creatorD, Weight: 1},
+}
+
+// Cosine similarities of the coLiked pairs
+var (
+	cosAB = 3 / math.Sqrt(4*3)
+	cosAC = 2 / math.Sqrt(4*2)
+	cosBC = 2 / math.Sqrt(3*2)
+	cosAD = 1 / math.Sqrt(4*1)
+)
+
+func formatNeighbors(neighbors map[int][]Neighbor) string {
+	out := ""
+	for _, id := range []int{creatorA, creatorB, creatorC, creatorD} {
+		list, ok := neighbors[id]
+		if !ok {
+			continue
+		}
+		out += fmt.Sprintf("%d:", id)
+		for _, n := range list {
+			out += fmt.Sprintf(" %d(%.6f,%d)", n.CreatorID, n.Score, n.Support)
+		}
+		out += "\n"
+	}
+	return out
+}
+
+func TestItemSimilarities(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ItemCFOptions
+		want map[int][]Neighbor
+	}{
+		{
+			name: "without shrinkage scores are cosines",
+			opts: ItemCFOptions{Neighbors: 10},
+			want: map[int][]Neighbor{
+				creatorA: {{creatorB, cosAB, 3}, {creatorC, cosAC, 2}, {creatorD, cosAD, 1}},
+				creatorB: {{creatorA, cosAB, 3}, {creatorC, cosBC, 2}},
+				creatorC: {{creatorB, cosBC, 2}, {creatorA, cosAC, 2}},
+				creatorD: {{creatorA, cosAD, 1}},
+			},
+		},
+		{
+			name: "shrinkage scales by support",
+			opts: ItemCFOptions{Neighbors: 10, Shrinkage: 1},
+			want: map[int][]Neighbor{
+				creatorA: {{creatorB, cosAB * 3 / 4, 3}, {creatorC, cosAC * 2 / 3, 2}, {creatorD, cosAD * 1 / 2, 1}},
+				creatorB: {{creatorA, cosAB * 3 / 4, 3}, {creatorC, cosBC * 2 / 3, 2}},
+				creatorC: {{creatorB, cosBC * 2 / 3, 2}, {creatorA, cosAC * 2 / 3, 2}},
+				creatorD: {{creatorA, cosAD * 1 / 2, 1}},
+			},
+		},
+		{
+			// At a shrinkage equal to the support, a similarity counts half
+			name: "shrinkage pulls less supported pairs further",
+			opts: ItemCFOptions{Neighbors: 10, Shrinkage: 3},
+			want: map[int][]Neighbor{
+				creatorA: {{creatorB, cosAB / 2, 3}, {creatorC, cosAC * 2 / 5, 2}, {creatorD, cosAD / 4, 1}},
+				creatorB: {{creatorA, cosAB / 2, 3}, {creatorC, cosBC * 2 / 5, 2}},
+				creatorC: {{creatorB, cosBC * 2 / 5, 2}, {creatorA, cosAC * 2 / 5, 2}},
+				creatorD: {{creatorA, cosAD / 4, 1}},
+			},
+		},
+		{
+			name: "pairs below MinCoOccurrence are dropped",
+			opts: ItemCFOptions{Neighbors: 10, MinCoOccurrence: 2},
+			want: map[int][]Neighbor{
+				creatorA: {{creatorB, cosAB, 3}, {creatorC, cosAC, 2}},
+				creatorB: {{creatorA, cosAB, 3}, {creatorC, cosBC, 2}},
+				creatorC: {{creatorB, cosBC, 2}, {creatorA, cosAC, 2}},
+			},
+		},
+		{
+			name: "MinCoOccurrence is inclusive",
+			opts: ItemCFOptions{Neighbors: 10, MinCoOccurrence: 3},
+			want: map[int][]Neighbor{
+				creatorA: {{creatorB, cosAB, 3}},
+				creatorB: {{creatorA, cosAB, 3}},
+			},
+		},
+		{
+			name: "lists are truncated to the top neighbors",
+			opts: ItemCFOptions{Neighbors: 1},
+			want: map[int][]Neighbor{
+				creatorA: {{creatorB, cosAB, 3}},
+				creatorB: {{creatorA, cosAB, 3}},
+				creatorC: {{creatorB, cosBC, 2}},
+				creatorD: {{creatorA, cosAD, 1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ItemSimilarities(coLiked, tt.opts)
+			if g, w := formatNeighbors(got), formatNeighbors(tt.want); g != w {
+				t.Errorf("got\n%swant\n%s", g, w)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got neighbors for %d creators, want %d", len(got), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestItemSimilaritiesTies(t *testing.T) {
+	// B and C are interchangeable for A, so the lower ID comes first and
+	// survives truncation
+	interactions := []Interaction{
+		{UserID: 1, CreatorID: creatorA, Weight: 1},
+		{UserID: 1, CreatorID: creatorC, Weight: 1},
+		{UserID: 2, CreatorID: creatorA, Weight: 1},
+		{UserID: 2, CreatorID: creatorB, Weight: 1},
+	}
+
+	got := ItemSimilarities(interactions, ItemCFOptions{Neighbors: 1})
+	if list := got[creatorA]; len(list) != 1 || list[0].CreatorID != creatorB {
+		t.Errorf("got neighbors of A %v, want only B", list)
+	}
+}
+
+func TestItemSimilaritiesSymmetric(t *testing.T) {
+	// Weighted interactions, with one ignored for its weight of 0
+	interactions := append([]Interaction{
+		{UserID: 5, CreatorID: creatorB, Weight: 2.5},
+		{UserID: 5, CreatorID: creatorD, Weight: 0.5},
+		{UserID: 6, CreatorID: creatorC, Weight: 1.5},
+		{UserID: 6, CreatorID: creatorD, Weight: 2},
+		{UserID: 6, CreatorID: creatorA, Weight: 0},
+	}, coLiked...)
+
+	neighbors := ItemSimilarities(interactions, ItemCFOptions{Neighbors: 10, Shrinkage: 2})
+	for id, list := range neighbors {
+		for _, n := range list {
+			found := false
+			for _, back := range neighbors[n.CreatorID] {
+				if back.CreatorID != id {
+					continue
+				}
+				found = true
+				if back.Score != n.Score || back.Support != n.Support {
+					t.Errorf("%d->%d is (%v, %d) but %d->%d is (%v, %d)",
+						id, n.CreatorID, n.Score, n.Support, n.CreatorID, id, back.Score, back.Support)
+				}
+			}
+			if !found {
+				t.Errorf("%d lists %d as a neighbor but not the reverse", id, n.CreatorID)
+			}
+			if n.Score <= 0 || n.Score > 1 {
+				t.Errorf("%d->%d score %v outside (0, 1]", id, n.CreatorID, n.Score)
+			}
+		}
+	}
+	if len(neighbors) != 4 {
+		t.Errorf("got neighbors for %d creators, want 4", len(neighbors))
+	}
+}
@@ -0,0 +1,225 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Strategy recommends creators to users after learning from a dataset
+type Strategy interface {
+	// Fit prepares the strategy to recommend from ds
+	Fit(ds *Dataset)
+	// Recommend returns up to k creators the user has not seen, best first
+	Recommend(userID, k int) []int
+}
+
+// StrategyOptions configures the strategies that need it, mirroring the
+// recommendations config
+type StrategyOptions struct {
+	CF        ItemCFOptions
+	TagWeight float64
+	CFWeight  float64
+}
+
+type registration struct {
+	name        string
+	newStrategy func(StrategyOptions) Strategy
+}
+
+var registry []registration
+
+// Register makes a strategy available under name. Strategies are listed in
+// the order they were registered.
+func Register(name string, newStrategy func(StrategyOptions) Strategy) {
+	registry = append(registry, registration{name, newStrategy})
+}
+
+// Strategies returns the names of all registered strategies
+func Strategies() []string {
+	names := make([]string, len(registry))
+	for i, r := range registry {
+		names[i] = r.name
+	}
+	return names
+}
+
+// NewStrategy creates the strategy registered under name
+func NewStrategy(name string, opts StrategyOptions) (Strategy, error) {
+	for _, r := range registry {
+		if r.name == name {
+			return r.newStrategy(opts), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown strategy %q", name)
+}
+
+func init() {
+	Register("popularity", func(StrategyOptions) Strategy { return &popularity{} })
+	Register("tag_profile", func(StrategyOptions) Strategy { return &tagProfile{} })
+	Register("item_cf", func(opts StrategyOptions) Strategy { return &itemCF{opts: opts.CF} })
+	Register("blend", func(opts StrategyOptions) Strategy {
+		return &blend{tag: &tagProfile{}, cf: &itemCF{opts: opts.CF}, tagWeight: opts.TagWeight, cfWeight: opts.CFWeight}
+	})
+}
+
+// popularity recommends the creators most users interacted with
+type popularity struct {
+	ds *Dataset
+}
+
+func (p *popularity) Fit(ds *Dataset) {
+	p.ds = ds
+}
+
+func (p *popularity) Recommend(userID, k int) []int {
+	scores := make(map[int]float64, len(p.ds.Popularity))
+	for creatorID, users := range p.ds.Popularity {
+		scores[creatorID] = float64(users)
+	}
+	return topK(scores, p.ds.Seen[userID], k)
+}
+
+// tagProfile ranks creators by the cosine similarity of their tag vectors to
+// the user's IDF-weighted tag profile, like the tag score of live
+// recommendations
+type tagProfile struct {
+	ds *Dataset
+	// Creators carrying each tag
+	byTag map[int][]int
+	norms map[int]float64
+}
+
+func (t *tagProfile) Fit(ds *Dataset) {
+	t.ds = ds
+	t.byTag = map[int][]int{}
+	t.norms = map[int]float64{}
+	for creatorID, vector := range ds.TagVectors {
+		for tagID := range vector {
+			t.byTag[tagID] = append(t.byTag[tagID], creatorID)
+		}
+		t.norms[creatorID] = norm(vector)
+	}
+}
+
+func (t *tagProfile) scores(userID int) map[int]float64 {
+	profile := map[int]float64{}
+	for tagID, signal := range t.ds.Profiles[userID] {
+		if idf := t.ds.IDF[tagID]; idf > 0 {
+			profile[tagID] = signal * idf
+		}
+	}
+	profileNorm := norm(profile)
+	if profileNorm == 0 {
+		return nil
+	}
+
+	scores := map[int]float64{}
+	for _, tagID := range sortedKeys(profile) {
+		for _, creatorID := range t.byTag[tagID] {
+			scores[creatorID] += profile[tagID] * t.ds.TagVectors[creatorID][tagID]
+		}
+	}
+	for creatorID := range scores {
+		scores[creatorID] /= profileNorm * t.norms[creatorID]
+	}
+	return scores
+}
+
+func (t *tagProfile) Recommend(userID, k int) []int {
+	return topK(t.scores(userID), t.ds.Seen[userID], k)
+}
+
+// itemCF scores creators by their collaborative filtering similarity to the
+// creators the user interacted with, averaged over the user's interactions,
+// like the CF score of live recommendations
+type itemCF struct {
+	opts      ItemCFOptions
+	ds        *Dataset
+	neighbors map[int][]Neighbor
+	byUser    map[int][]Interaction
+}
+
+func (f *itemCF) Fit(ds *Dataset) {
+	f.ds = ds
+	f.neighbors = ItemSimilarities(ds.Interactions, f.opts)
+	f.byUser = map[int][]Interaction{}
+	for _, in := range ds.Interactions {
+		f.byUser[in.UserID] = append(f.byUser[in.UserID], in)
+	}
+}
+
+func (f *itemCF) scores(userID int) map[int]float64 {
+	total := 0.0
+	scores := map[int]float64{}
+	for _, in := range f.byUser[userID] {
+		total += in.Weight
+		for _, n := range f.neighbors[in.CreatorID] {
+			scores[n.CreatorID] += n.Score * in.Weight
+		}
+	}
+	for creatorID := range scores {
+		scores[creatorID] /= total
+	}
+	return scores
+}
+
+func (f *itemCF) Recommend(userID, k int) []int {
+	return topK(f.scores(userID), f.ds.Seen[userID], k)
+}
+
+// blend weighs the tag profile and collaborative filtering scores, like
+// live recommendations
+type blend struct {
+	tag                 *tagProfile
+	cf                  *itemCF
+	tagWeight, cfWeight float64
+	ds                  *Dataset
+}
+
+func (b *blend) Fit(ds *Dataset) {
+	b.ds = ds
+	b.tag.Fit(ds)
+	b.cf.Fit(ds)
+}
+
+func (b *blend) Recommend(userID, k int) []int {
+	scores := map[int]float64{}
+	for creatorID, score := range b.tag.scores(userID) {
+		scores[creatorID] += b.tagWeight * math.Min(score, 1)
+	}
+	for creatorID, score := range b.cf.scores(userID) {
+		scores[creatorID] += b.cfWeight * score
+	}
+	return topK(scores, b.ds.Seen[userID], k)
+}
+
+// topK returns the k creators with the highest positive scores that are not
+// in seen, ties broken by creator ID
+func topK(scores map[int]float64, seen map[int]bool, k int) []int {
+	var ids []int
+	for creatorID, score := range scores {
+		if score > 0 && !seen[creatorID] {
+			ids = append(ids, creatorID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > k {
+		ids = ids[:k]
+	}
+	return ids
+}
+
+func sortedKeys(m map[int]float64) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,40 @@
+package refresh
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
+)
+
+// quotaBudget caps the API units the refresher spends per quota day, on top
+// of the global youtube.Quota ledger shared with interactive requests
+type quotaBudget struct {
+	mu    sync.Mutex
+	limit int
+	spent int
+	day   string
+}
+
+func newQuotaBudget(limit int) *quotaBudget {
+	return &quotaBudget{limit: limit}
+}
+
+// spend reserves units from today's budget and reports whether they were available.
+// A limit of zero or less means unlimited.
+func (b *quotaBudget) spend(units int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	today := youtube.QuotaDay(time.Now())
+	if today != b.day {
+		b.day = today
+		b.spent = 0
+	}
+
+	if b.limit > 0 && b.spent+units > b.limit {
+		return false
+	}
+	b.spent += units
+	return true
+}
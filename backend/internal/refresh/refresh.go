@@ -0,0 +1,173 @@
+// Package refresh keeps stored creator metadata in sync with YouTube.
+package refresh
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/ingest"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+)
+
+const (
+	// channelsListCost is the quota cost of one channels.list call
+	channelsListCost = 1
+	// uploadsSyncCost is the typical quota cost of an incremental uploads sync:
+	// one playlistItems.list page plus one videos.list call for durations
+	uploadsSyncCost = 2
+)
+
+// Refresher periodically re-fetches channel metadata for stored creators
+type Refresher struct {
+	client youtube.Client
+	cfg    config.RefreshConfig
+	budget *quotaBudget
+}
+
+// New creates a Refresher that uses client to fetch channel metadata
+func New(client youtube.Client, cfg config.RefreshConfig) *Refresher {
+	return &Refresher{
+		client: client,
+		cfg:    cfg,
+		budget: newQuotaBudget(cfg.DailyQuota),
+	}
+}
+
+// Start runs the refresher in the background until ctx is cancelled
+func Start(ctx context.Context, client youtube.Client, cfg config.RefreshConfig) {
+	if !cfg.Enabled {
+		logger.Log.Info("Creator refresher disabled")
+		return
+	}
+	if cfg.PollInterval <= 0 {
+		logger.Log.Error("Creator refresher not started, refresh.poll_interval must be positive", "poll_interval", cfg.PollInterval)
+		return
+	}
+
+	go New(client, cfg).Run(ctx)
+}
+
+// Run refreshes stale creators immediately and then on every poll interval
+func (r *Refresher) Run(ctx context.Context) {
+	logger.Log.Info("Creator refresher started", "interval", r.cfg.Interval, "daily_quota", r.cfg.DailyQuota)
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RefreshStale(ctx); err != nil {
+			logger.Log.Error("Creator refresh pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshStale walks all creators not refreshed within the configured interval
+// in batches of youtube.MaxBatchSize, stopping early when the daily quota is spent
+func (r *Refresher) RefreshStale(ctx context.Context) error {
+	staleBefore := time.Now().Add(-r.cfg.Interval)
+	afterID := 0
+	refreshed := 0
+
+	for {
+		creators, err := db.ListCreatorsToRefresh(ctx, staleBefore, afterID, youtube.MaxBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(creators) == 0 {
+			break
+		}
+
+		if !r.budget.spend(channelsListCost) {
+			logger.Log.Warn("Creator refresh paused, daily quota budget spent", "refreshed", refreshed)
+			return nil
+		}
+
+		if err := r.refreshBatch(ctx, creators); err != nil {
+			if errors.Is(err, youtube.ErrQuotaExceeded) {
+				logger.Log.Warn("Creator refresh paused, YouTube quota exhausted", "refreshed", refreshed)
+				return nil
+			}
+			return err
+		}
+
+		refreshed += len(creators)
+		afterID = creators[len(creators)-1].ID
+	}
+
+	if refreshed > 0 {
+		logger.Log.Info("Creator refresh pass complete", "refreshed", refreshed)
+	}
+	return nil
+}
+
+// refreshBatch fetches one batch of channels and writes the results back
+func (r *Refresher) refreshBatch(ctx context.Context, creators []db.Creator) error {
+	ids := make([]string, len(creators))
+	for i, creator := range creators {
+		ids[i] = creator.YouTubeID
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	channels, err := r.client.FetchChannels(callCtx, ids)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		found[channel.ID] = true
+
+		creatorID, err := ingest.RefreshChannel(ctx, &channel)
+		if err != nil {
+			// Keep going, one bad row should not stall the whole pass or
+			// be retried on every poll
+			logger.Log.Error("Failed to refresh creator", "youtube_id", channel.ID, "error", err)
+			if err := db.MarkCreatorRefreshFailed(ctx, channel.ID); err != nil {
+				logger.Log.Error("Failed to record refresh attempt", "youtube_id", channel.ID, "error", err)
+			}
+			continue
+		}
+
+		r.syncUploads(ctx, creatorID, channel.UploadsPlaylistID)
+	}
+
+	// Channels missing from the response were deleted or terminated
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		logger.Log.Info("Marking unavailable channels", "youtube_ids", missing)
+		return db.MarkCreatorsUnavailable(ctx, missing)
+	}
+
+	return nil
+}
+
+// syncUploads pulls a creator's new uploads if the quota budget allows it
+func (r *Refresher) syncUploads(ctx context.Context, creatorID int, uploadsPlaylistID string) {
+	if !r.budget.spend(uploadsSyncCost) {
+		return
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := ingest.SyncUploads(callCtx, r.client, creatorID, uploadsPlaylistID); err != nil {
+		logger.Log.Error("Failed to sync uploads", "creator_id", creatorID, "error", err)
+	}
+}
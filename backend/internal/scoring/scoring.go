@@ -0,0 +1,70 @@
+// Package scoring ranks tags on creators from their votes.
+package scoring
+
+import (
+	"math"
+	"time"
+)
+
+// z is the standard normal quantile for a 95% confidence level
+const z = 1.959963984540054
+
+// Wilson returns the lower bound of the Wilson score interval for the share
+// of upvotes: how positive the votes are, discounted by how few there are.
+// It is 0 without votes. Weighted counts are accepted.
+func Wilson(up, down float64) float64 {
+	n := up + down
+	if n <= 0 {
+		return 0
+	}
+
+	p := up / n
+	z2 := z * z
+	lower := (p + z2/(2*n) - z*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+	return math.Max(0, lower) // Rounding can dip just below 0 without upvotes
+}
+
+// DecayWeight is the weight of a vote cast age ago: 1 when new, halving
+// every halfLife. A halfLife of zero or less disables decay.
+func DecayWeight(age, halfLife time.Duration) float64 {
+	if halfLife <= 0 || age <= 0 {
+		return 1
+	}
+	return math.Exp2(-age.Hours() / halfLife.Hours())
+}
+
+// Vote is a single vote on a creator tag
+type Vote struct {
+	Type int       // 1 for up, -1 for down
+	At   time.Time // When the vote was cast or last changed
+}
+
+// Tally sums the votes on a creator tag, plainly and with time decay
+type Tally struct {
+	Up          int
+	Down        int
+	DecayedUp   float64
+	DecayedDown float64
+	DecayedAt   time.Time // The time the decayed sums are valid for
+}
+
+// Count tallies votes as of now
+func Count(votes []Vote, now time.Time, halfLife time.Duration) Tally {
+	t := Tally{DecayedAt: now}
+	for _, v := range votes {
+		w := DecayWeight(now.Sub(v.At), halfLife)
+		if v.Type > 0 {
+			t.Up++
+			t.DecayedUp += w
+		} else {
+			t.Down++
+			t.DecayedDown += w
+		}
+	}
+	return t
+}
+
+// Wilson returns the Wilson lower bound of the plain vote counts
+func (t Tally) Wilson() float64 {
+	return Wilson(float64(t.Up), float64(t.Down))
+}
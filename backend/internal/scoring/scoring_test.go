@@ -0,0 +1,103 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWilson(t *testing.T) {
+	tests := []struct {
+		up, down float64
+		want     float64
+	}{
+		{up: 0, down: 0, want: 0},
+		{up: 1, down: 0, want: 0.206549},
+		{up: 5, down: 0, want: 0.565518},
+		{up: 10, down: 10, want: 0.299298},
+		{up: 90, down: 10, want: 0.825634},
+		{up: 0, down: 5, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := Wilson(tt.up, tt.down); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("Wilson(%v, %v) = %.6f, want %.6f", tt.up, tt.down, got, tt.want)
+		}
+	}
+}
+
+func TestWilsonMonotonic(t *testing.T) {
+	for down := 0.0; down <= 20; down += 5 {
+		prev := Wilson(0, down)
+		for up := 1.0; up <= 50; up++ {
+			got := Wilson(up, down)
+			if got <= prev {
+				t.Fatalf("Wilson(%v, %v) = %v, not above Wilson(%v, %v) = %v", up, down, got, up-1, down, prev)
+			}
+			if got >= 1 {
+				t.Fatalf("Wilson(%v, %v) = %v, want below 1", up, down, got)
+			}
+			prev = got
+		}
+	}
+
+	// More downvotes never help
+	if Wilson(10, 1) <= Wilson(10, 2) {
+		t.Errorf("Wilson(10, 1) = %v, not above Wilson(10, 2) = %v", Wilson(10, 1), Wilson(10, 2))
+	}
+}
+
+func TestDecayWeight(t *testing.T) {
+	halfLife := 90 * 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		age      time.Duration
+		halfLife time.Duration
+		want     float64
+	}{
+		{name: "new", age: 0, halfLife: halfLife, want: 1},
+		{name: "one half-life", age: halfLife, halfLife: halfLife, want: 0.5},
+		{name: "two half-lives", age: 2 * halfLife, halfLife: halfLife, want: 0.25},
+		{name: "future votes count fully", age: -time.Hour, halfLife: halfLife, want: 1},
+		{name: "decay disabled", age: 10 * halfLife, halfLife: 0, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecayWeight(tt.age, tt.halfLife); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("DecayWeight(%s, %s) = %v, want %v", tt.age, tt.halfLife, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := 10 * 24 * time.Hour
+
+	votes := []Vote{
+		{Type: 1, At: now},
+		{Type: 1, At: now.Add(-halfLife)},
+		{Type: 1, At: now.Add(-2 * halfLife)},
+		{Type: -1, At: now.Add(-halfLife)},
+	}
+
+	got := Count(votes, now, halfLife)
+	if got.Up != 3 || got.Down != 1 {
+		t.Errorf("got %d up and %d down, want 3 and 1", got.Up, got.Down)
+	}
+	if math.Abs(got.DecayedUp-1.75) > 1e-12 || math.Abs(got.DecayedDown-0.5) > 1e-12 {
+		t.Errorf("got decayed %v up and %v down, want 1.75 and 0.5", got.DecayedUp, got.DecayedDown)
+	}
+	if !got.DecayedAt.Equal(now) {
+		t.Errorf("got DecayedAt %s, want %s", got.DecayedAt, now)
+	}
+	if want := Wilson(3, 1); got.Wilson() != want {
+		t.Errorf("Tally.Wilson() = %v, want %v", got.Wilson(), want)
+	}
+
+	if empty := Count(nil, now, halfLife); empty.Up != 0 || empty.Down != 0 || empty.Wilson() != 0 {
+		t.Errorf("Count(nil) = %+v, want an empty tally", empty)
+	}
+}
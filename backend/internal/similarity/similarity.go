@@ -0,0 +1,152 @@
+// Package similarity keeps the precomputed similar-creator table in sync
+// with creator tags and votes.
+package similarity
+
+import (
+	"context"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/db"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/logger"
+)
+
+// Indexer rebuilds the neighbors of creators whose tags or votes changed,
+// and periodically of every creator
+type Indexer struct {
+	cfg         config.SimilarityConfig
+	lastRebuild time.Time
+}
+
+// New creates an Indexer
+func New(cfg config.SimilarityConfig) *Indexer {
+	return &Indexer{cfg: cfg}
+}
+
+// Start runs the indexer in the background until ctx is cancelled
+func Start(ctx context.Context, cfg config.SimilarityConfig) {
+	if !cfg.Enabled {
+		logger.Log.Info("Similarity indexer disabled")
+		return
+	}
+	if cfg.PollInterval <= 0 {
+		logger.Log.Error("Similarity indexer not started, similarity.poll_interval must be positive", "poll_interval", cfg.PollInterval)
+		return
+	}
+	if cfg.BatchSize <= 0 {
+		logger.Log.Error("Similarity indexer not started, similarity.batch_size must be positive", "batch_size", cfg.BatchSize)
+		return
+	}
+	if cfg.Neighbors <= 0 {
+		logger.Log.Error("Similarity indexer not started, similarity.neighbors must be positive", "neighbors", cfg.Neighbors)
+		return
+	}
+
+	go New(cfg).Run(ctx)
+}
+
+// Run processes the rebuild queue immediately and then on every poll interval
+func (ix *Indexer) Run(ctx context.Context) {
+	logger.Log.Info("Similarity indexer started", "interval", ix.cfg.PollInterval, "neighbors", ix.cfg.Neighbors)
+
+	ticker := time.NewTicker(ix.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := ix.Pass(ctx); err != nil {
+			logger.Log.Error("Similarity pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Pass queues every creator when a full rebuild is due, then rebuilds queued
+// creators in batches until the queue is empty. A creator that fails is
+// moved to the back of the queue and retried on the next pass.
+func (ix *Indexer) Pass(ctx context.Context) error {
+	if err := ix.queueRebuild(ctx); err != nil {
+		return err
+	}
+
+	rebuilt := 0
+	failed := map[int]bool{}
+	for {
+		queued, err := db.NextQueuedCreators(ctx, ix.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		// Creators that failed in this pass were moved to the back, so a
+		// batch of only those means everything else has been rebuilt
+		var pending []db.QueuedCreator
+		for _, creator := range queued {
+			if !failed[creator.ID] {
+				pending = append(pending, creator)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		ids := make([]int, len(pending))
+		for i, creator := range pending {
+			ids[i] = creator.ID
+		}
+		if err := db.RefreshTagIDF(ctx, ids); err != nil {
+			return err
+		}
+
+		for _, creator := range pending {
+			if err := db.RebuildNeighbors(ctx, creator, ix.cfg.Neighbors); err != nil {
+				logger.Log.Error("Failed to rebuild similar creators", "creator_id", creator.ID, "error", err)
+				failed[creator.ID] = true
+				if err := db.RequeueSimilarity(ctx, creator.ID); err != nil {
+					return err
+				}
+				continue
+			}
+			rebuilt++
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	if rebuilt > 0 || len(failed) > 0 {
+		logger.Log.Info("Similarity pass complete", "rebuilt", rebuilt, "failed", len(failed))
+	}
+	return nil
+}
+
+// queueRebuild queues every creator when nothing has been built yet or the
+// rebuild interval has passed. Full rebuilds refresh tag IDF and the vector
+// norms that incremental rebuilds leave as they were.
+func (ix *Indexer) queueRebuild(ctx context.Context) error {
+	if ix.lastRebuild.IsZero() {
+		built, err := db.SimilarityBuilt(ctx)
+		if err != nil {
+			return err
+		}
+		if built {
+			// Count the server's start as the last rebuild, so restarts
+			// don't each rebuild everything
+			ix.lastRebuild = time.Now()
+			return nil
+		}
+	} else if time.Since(ix.lastRebuild) < ix.cfg.RebuildInterval {
+		return nil
+	}
+
+	if err := db.QueueAllSimilarities(ctx); err != nil {
+		return err
+	}
+	ix.lastRebuild = time.Now()
+	logger.Log.Info("Queued all creators for similarity rebuild")
+	return nil
+}
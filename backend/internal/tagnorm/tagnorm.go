@@ -0,0 +1,79 @@
+// Package tagnorm normalizes user-submitted tag names so spelling variants
+// like "Tech", "tech " and "ＴＥＣＨ" map to one canonical tag.
+package tagnorm
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the longest tag name accepted, in characters after normalization
+const MaxLength = 50
+
+var (
+	// ErrEmpty is returned for tags without any letters or digits
+	ErrEmpty = errors.New("tag is empty after normalization")
+	// ErrTooLong is returned for tags longer than MaxLength
+	ErrTooLong = errors.New("tag is too long")
+)
+
+// Tag is a normalized tag
+type Tag struct {
+	Name string // Display name: NFKC with whitespace collapsed, case preserved
+	Slug string // Canonical key: case-folded, punctuation runs replaced by '-'
+}
+
+// foldCase applies full Unicode case folding, so "Straße" and "STRASSE" agree
+var foldCase = cases.Fold()
+
+// Normalize turns a raw tag into its display name and slug
+func Normalize(raw string) (Tag, error) {
+	name := strings.Join(strings.FieldsFunc(norm.NFKC.String(raw), isSeparator), " ")
+	if len([]rune(name)) > MaxLength {
+		return Tag{}, ErrTooLong
+	}
+
+	slug := Slug(name)
+	if slug == "" {
+		return Tag{}, ErrEmpty
+	}
+
+	return Tag{Name: name, Slug: slug}, nil
+}
+
+// Slug returns the canonical key of a tag name. Letters, digits, combining
+// marks and the '+' and '#' of names like "C++" and "C#" are kept; every
+// other run of characters becomes a single '-'.
+func Slug(name string) string {
+	folded := foldCase.String(norm.NFKC.String(name))
+
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range folded {
+		if !isSlugRune(r) {
+			pendingDash = b.Len() > 0
+			continue
+		}
+		if pendingDash {
+			b.WriteByte('-')
+			pendingDash = false
+		}
+		b.WriteRune(r)
+	}
+
+	// NFKC again since folding can produce unnormalized sequences
+	return norm.NFKC.String(b.String())
+}
+
+func isSlugRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r) || r == '+' || r == '#'
+}
+
+// isSeparator reports whitespace and invisible control characters
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsControl(r) || r == '\u200b' || r == '\ufeff'
+}
@@ -0,0 +1,72 @@
+package tagnorm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantName string
+		wantSlug string
+	}{
+		{name: "plain", raw: "cooking", wantName: "cooking", wantSlug: "cooking"},
+		{name: "width folding", raw: "ＴＥＣＨ", wantName: "TECH", wantSlug: "tech"},
+		{name: "case folding keeps the display name", raw: "Tech", wantName: "Tech", wantSlug: "tech"},
+		{name: "full case folding", raw: "STRASSE", wantName: "STRASSE", wantSlug: "strasse"},
+		{name: "sharp s folds like ss", raw: "Straße", wantName: "Straße", wantSlug: "strasse"},
+		{name: "C++", raw: "C++", wantName: "C++", wantSlug: "c++"},
+		{name: "C#", raw: "C#", wantName: "C#", wantSlug: "c#"},
+		{name: "whitespace collapsed", raw: "  machine \t\n learning ", wantName: "machine learning", wantSlug: "machine-learning"},
+		{name: "invisible characters are separators", raw: "machine\u200blearning", wantName: "machine learning", wantSlug: "machine-learning"},
+		{name: "punctuation runs become one dash", raw: "rock & roll!", wantName: "rock & roll!", wantSlug: "rock-roll"},
+		{name: "composed and decomposed accents agree", raw: "cafe\u0301", wantName: "café", wantSlug: "café"},
+		{name: "exactly MaxLength", raw: strings.Repeat("a", MaxLength), wantName: strings.Repeat("a", MaxLength), wantSlug: strings.Repeat("a", MaxLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, err := Normalize(tt.raw)
+			if err != nil {
+				t.Fatalf("Normalize(%q): %v", tt.raw, err)
+			}
+			if tag.Name != tt.wantName || tag.Slug != tt.wantSlug {
+				t.Errorf("Normalize(%q) = %q / %q, want %q / %q", tt.raw, tag.Name, tag.Slug, tt.wantName, tt.wantSlug)
+			}
+		})
+	}
+}
+
+func TestNormalizeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{name: "empty", raw: "", wantErr: ErrEmpty},
+		{name: "only whitespace", raw: " \t ", wantErr: ErrEmpty},
+		{name: "only punctuation", raw: "!!! ---", wantErr: ErrEmpty},
+		{name: "one over MaxLength", raw: strings.Repeat("é", MaxLength+1), wantErr: ErrTooLong},
+		{name: "over MaxLength after folding width", raw: strings.Repeat("ａ", MaxLength+1), wantErr: ErrTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Normalize(tt.raw); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Normalize(%q) error = %v, want %v", tt.raw, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSlugDistinguishesVariants(t *testing.T) {
+	if Slug("C++") == Slug("C#") || Slug("C++") == Slug("C") {
+		t.Errorf("C++, C# and C share slugs: %q, %q, %q", Slug("C++"), Slug("C#"), Slug("C"))
+	}
+	if got, want := Slug("Machine-Learning"), Slug("machine learning"); got != want {
+		t.Errorf("Slug(Machine-Learning) = %q, want %q", got, want)
+	}
+}
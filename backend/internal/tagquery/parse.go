@@ -0,0 +1,348 @@
+// Package tagquery parses boolean tag search queries such as
+// `cooking AND (vegan OR budget) -asmr`.
+//
+// Terms are tags, quoted when they contain spaces ("machine learning"), and
+// may carry a minimum score: vegan>=0.6 only matches creators on which the
+// vegan tag scores at least 0.6. Terms next to each other are ANDed; AND, OR
+// and NOT must be upper case, and a leading '-' is short for NOT. NOT binds
+// tightest, then AND, then OR.
+package tagquery
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/tagnorm"
+)
+
+// Limits on query size
+const (
+	MaxLength = 500 // Characters
+	MaxTerms  = 20
+)
+
+// Node is a node of a parsed query
+type Node interface {
+	node()
+}
+
+// Tag matches creators carrying a tag, optionally with a minimum score
+type Tag struct {
+	Name     string   // As written, normalized
+	Slug     string   // Canonical slug used for matching
+	MinScore *float64 // Minimum score of the tag on the creator, if set
+	Pos      int
+}
+
+// And matches creators matching both sides
+type And struct{ Left, Right Node }
+
+// Or matches creators matching either side
+type Or struct{ Left, Right Node }
+
+// Not matches creators that do not match Expr
+type Not struct{ Expr Node }
+
+func (Tag) node() {}
+func (And) node() {}
+func (Or) node()  {}
+func (Not) node() {}
+
+// SyntaxError reports where a query could not be parsed. Pos is the
+// 1-based character position of the problem.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// Parse parses a query into its syntax tree
+func Parse(query string) (Node, error) {
+	if utf8.RuneCountInString(query) > MaxLength {
+		return nil, &SyntaxError{Pos: MaxLength + 1, Msg: fmt.Sprintf("query is longer than %d characters", MaxLength)}
+	}
+
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, end: utf8.RuneCountInString(query) + 1}
+	if p.peek().kind == tokEOF {
+		return nil, &SyntaxError{Pos: 1, Msg: "query is empty"}
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+	}
+
+	if p.terms > MaxTerms {
+		return nil, &SyntaxError{Pos: 1, Msg: fmt.Sprintf("query has more than %d tags", MaxTerms)}
+	}
+	if len(PositiveTags(node)) == 0 {
+		return nil, &SyntaxError{Pos: 1, Msg: "query must include a tag to match, not only exclusions"}
+	}
+
+	return node, nil
+}
+
+// PositiveTags returns the tags a matching creator may carry: every tag
+// that is not negated
+func PositiveTags(n Node) []Tag {
+	var tags []Tag
+	var walk func(Node, bool)
+	walk = func(n Node, negated bool) {
+		switch n := n.(type) {
+		case Tag:
+			if !negated {
+				tags = append(tags, n)
+			}
+		case And:
+			walk(n.Left, negated)
+			walk(n.Right, negated)
+		case Or:
+			walk(n.Left, negated)
+			walk(n.Right, negated)
+		case Not:
+			walk(n.Expr, !negated)
+		}
+	}
+	walk(n, false)
+	return tags
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+	tokGTE
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	quoted bool
+	pos    int // 1-based character position
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokLParen, tokRParen, tokGTE:
+		return fmt.Sprintf("'%s'", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func lex(query string) ([]token, error) {
+	runes := []rune(query)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			kind := tokLParen
+			if r == ')' {
+				kind = tokRParen
+			}
+			tokens = append(tokens, token{kind: kind, text: string(r), pos: pos})
+			i++
+		case r == '>' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, token{kind: tokGTE, text: ">=", pos: pos})
+			i += 2
+		case r == '-':
+			// Tags never start with '-', so one at the start of a token negates
+			tokens = append(tokens, token{kind: tokNot, text: "-", pos: pos})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, &SyntaxError{Pos: pos, Msg: "unterminated quote"}
+			}
+			tokens = append(tokens, token{kind: tokWord, text: string(runes[i+1 : end]), quoted: true, pos: pos})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !endsWord(runes, end) {
+				end++
+			}
+			text := string(runes[i:end])
+			kind := tokWord
+			switch text {
+			case "AND":
+				kind = tokAnd
+			case "OR":
+				kind = tokOr
+			case "NOT":
+				kind = tokNot
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: pos})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+func endsWord(runes []rune, i int) bool {
+	r := runes[i]
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' ||
+		(r == '>' && i+1 < len(runes) && runes[i+1] == '=')
+}
+
+type parser struct {
+	tokens []token
+	next   int
+	end    int // Position reported for errors at the end of the query
+	terms  int
+}
+
+func (p *parser) peek() token {
+	if p.next < len(p.tokens) {
+		return p.tokens[p.next]
+	}
+	return token{kind: tokEOF, pos: p.end}
+}
+
+func (p *parser) advance() token {
+	tok := p.peek()
+	if p.next < len(p.tokens) {
+		p.next++
+	}
+	return tok
+}
+
+// parseOr := and { OR and }
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd := unary { [AND] unary }
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.advance()
+		case tokWord, tokNot, tokLParen:
+			// Juxtaposed terms are ANDed
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+}
+
+// parseUnary := (NOT | '-') unary | primary
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind == tokNot {
+		p.advance()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: expr}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary := '(' or ')' | tag [ '>=' number ]
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.advance()
+
+	switch tok.kind {
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next.kind != tokRParen {
+			return nil, &SyntaxError{Pos: next.pos, Msg: fmt.Sprintf("expected ')' to close the '(' at position %d, found %s", tok.pos, next)}
+		}
+		p.advance()
+		return expr, nil
+
+	case tokWord:
+		return p.parseTag(tok)
+
+	default:
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("expected a tag, found %s", tok)}
+	}
+}
+
+func (p *parser) parseTag(tok token) (Node, error) {
+	normalized, err := tagnorm.Normalize(tok.text)
+	if err != nil {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("invalid tag %q: %v", tok.text, err)}
+	}
+	p.terms++
+
+	tag := Tag{Name: normalized.Name, Slug: normalized.Slug, Pos: tok.pos}
+	if p.peek().kind != tokGTE {
+		return tag, nil
+	}
+
+	p.advance()
+	num := p.advance()
+	score, err := strconv.ParseFloat(strings.TrimSpace(num.text), 64)
+	if num.kind != tokWord || num.quoted || err != nil {
+		return nil, &SyntaxError{Pos: num.pos, Msg: fmt.Sprintf("expected a minimum score after '>=', found %s", num)}
+	}
+	if math.IsNaN(score) || score < 0 || score > 1 {
+		return nil, &SyntaxError{Pos: num.pos, Msg: "minimum score must be between 0 and 1"}
+	}
+	tag.MinScore = &score
+
+	return tag, nil
+}
@@ -0,0 +1,168 @@
+package tagquery
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// format writes a syntax tree as an s-expression of tag slugs
+func format(n Node) string {
+	switch n := n.(type) {
+	case Tag:
+		if n.MinScore != nil {
+			return n.Slug + ">=" + strconv.FormatFloat(*n.MinScore, 'g', -1, 64)
+		}
+		return n.Slug
+	case And:
+		return fmt.Sprintf("(and %s %s)", format(n.Left), format(n.Right))
+	case Or:
+		return fmt.Sprintf("(or %s %s)", format(n.Left), format(n.Right))
+	case Not:
+		return fmt.Sprintf("(not %s)", format(n.Expr))
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "single tag", query: "cooking", want: "cooking"},
+		{name: "NOT binds tighter than AND", query: "NOT a AND b", want: "(and (not a) b)"},
+		{name: "AND binds tighter than OR", query: "a OR b AND c", want: "(or a (and b c))"},
+		{name: "NOT binds tighter than OR", query: "NOT a OR b", want: "(or (not a) b)"},
+		{name: "AND and OR are left associative", query: "a OR b OR c AND d AND e", want: "(or (or a b) (and (and c d) e))"},
+		{name: "parentheses override precedence", query: "(a OR b) AND c", want: "(and (or a b) c)"},
+		{name: "implicit AND", query: "a b c", want: "(and (and a b) c)"},
+		{name: "implicit AND binds tighter than OR", query: "a b OR c", want: "(or (and a b) c)"},
+		{name: "implicit AND before parentheses", query: "a (b OR c)", want: "(and a (or b c))"},
+		{
+			name:  "example from the package doc",
+			query: "cooking AND (vegan OR budget) -asmr",
+			want:  "(and (and cooking (or vegan budget)) (not asmr))",
+		},
+		{name: "leading minus negates", query: "cooking -asmr", want: "(and cooking (not asmr))"},
+		{name: "minus on its own negates the next term", query: "cooking - asmr", want: "(and cooking (not asmr))"},
+		{name: "minus negates a group", query: "cooking -(asmr OR mukbang)", want: "(and cooking (not (or asmr mukbang)))"},
+		{name: "hyphen inside a term", query: "machine-learning", want: "machine-learning"},
+		{name: "hyphenated term negated", query: "rust -machine-learning", want: "(and rust (not machine-learning))"},
+		{name: "double negation", query: "NOT -asmr", want: "(not (not asmr))"},
+		{name: "quoted term with spaces", query: `"machine learning" rust`, want: "(and machine-learning rust)"},
+		{name: "quoted operator is a tag", query: `"AND" OR cooking`, want: "(or and cooking)"},
+		{name: "lower case operators are tags", query: "cats and dogs", want: "(and (and cats and) dogs)"},
+		{name: "tags are normalized", query: "ＴＥＣＨ OR C++ OR C#", want: "(or (or tech c++) c#)"},
+		{name: "score threshold", query: "vegan>=0.6", want: "vegan>=0.6"},
+		{name: "score threshold with spaces", query: "vegan >= 0.6 cooking", want: "(and vegan>=0.6 cooking)"},
+		{name: "score threshold on quoted term", query: `"machine learning">=0.25`, want: "machine-learning>=0.25"},
+		{name: "score threshold bounds", query: "a>=0 OR b>=1", want: "(or a>=0 b>=1)"},
+		{name: "negated score threshold", query: "cooking -asmr>=0.5", want: "(and cooking (not asmr>=0.5))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.query, err)
+			}
+			if got := format(node); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTagPositions(t *testing.T) {
+	node, err := Parse(`cooking -"machine learning"`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	and := node.(And)
+	if pos := and.Left.(Tag).Pos; pos != 1 {
+		t.Errorf("cooking at position %d, want 1", pos)
+	}
+	if pos := and.Right.(Not).Expr.(Tag).Pos; pos != 10 {
+		t.Errorf("machine learning at position %d, want 10", pos)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantPos int
+		wantMsg string
+	}{
+		{name: "empty", query: "", wantPos: 1, wantMsg: "query is empty"},
+		{name: "only spaces", query: "   ", wantPos: 1, wantMsg: "query is empty"},
+		{name: "trailing AND", query: "cooking AND", wantPos: 12, wantMsg: "expected a tag, found end of query"},
+		{name: "leading OR", query: "OR cooking", wantPos: 1, wantMsg: `expected a tag, found "OR"`},
+		{name: "doubled OR", query: "cooking OR OR vegan", wantPos: 12, wantMsg: `expected a tag, found "OR"`},
+		{name: "trailing minus", query: "cooking -", wantPos: 10, wantMsg: "expected a tag, found end of query"},
+		{name: "empty parentheses", query: "cooking AND ()", wantPos: 14, wantMsg: "expected a tag, found ')'"},
+		{
+			name:    "unclosed parenthesis",
+			query:   "(vegan OR budget",
+			wantPos: 17,
+			wantMsg: "expected ')' to close the '(' at position 1, found end of query",
+		},
+		{
+			name:    "nested unclosed parenthesis",
+			query:   "a ((b OR c) d",
+			wantPos: 14,
+			wantMsg: "expected ')' to close the '(' at position 3, found end of query",
+		},
+		{name: "unopened parenthesis", query: "vegan) cooking", wantPos: 6, wantMsg: "unexpected ')'"},
+		{name: "unterminated quote", query: `rust "machine learning`, wantPos: 6, wantMsg: "unterminated quote"},
+		{name: "tag without letters", query: `cooking AND "!!!"`, wantPos: 13, wantMsg: `invalid tag "!!!"`},
+		{name: "threshold above 1", query: "vegan>=1.5", wantPos: 8, wantMsg: "minimum score must be between 0 and 1"},
+		{name: "negative threshold", query: "vegan >= -0.5", wantPos: 10, wantMsg: `expected a minimum score after '>=', found "-"`},
+		{name: "NaN threshold", query: "vegan>=NaN", wantPos: 8, wantMsg: "minimum score must be between 0 and 1"},
+		{name: "threshold not a number", query: "vegan>=high", wantPos: 8, wantMsg: `expected a minimum score after '>=', found "high"`},
+		{name: "quoted threshold", query: `vegan>="0.5"`, wantPos: 8, wantMsg: `expected a minimum score after '>=', found "0.5"`},
+		{name: "missing threshold", query: "vegan>=", wantPos: 8, wantMsg: "expected a minimum score after '>=', found end of query"},
+		{name: "threshold without tag", query: ">=0.5", wantPos: 1, wantMsg: "expected a tag, found '>='"},
+		{name: "only exclusions", query: "-asmr NOT mukbang", wantPos: 1, wantMsg: "query must include a tag to match, not only exclusions"},
+		{name: "too long", query: strings.Repeat("a", MaxLength+1), wantPos: MaxLength + 1, wantMsg: "query is longer than 500 characters"},
+		{name: "too many tags", query: strings.Repeat("a ", MaxTerms+1), wantPos: 1, wantMsg: "query has more than 20 tags"},
+		{name: "position counts characters, not bytes", query: "café AND", wantPos: 9, wantMsg: "expected a tag, found end of query"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q) error = %v, want a *SyntaxError", tt.query, err)
+			}
+			if syntaxErr.Pos != tt.wantPos {
+				t.Errorf("Parse(%q) error at position %d, want %d (%s)", tt.query, syntaxErr.Pos, tt.wantPos, syntaxErr.Msg)
+			}
+			if !strings.HasPrefix(syntaxErr.Msg, tt.wantMsg) {
+				t.Errorf("Parse(%q) error %q, want %q", tt.query, syntaxErr.Msg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestPositiveTags(t *testing.T) {
+	node, err := Parse("cooking AND (vegan OR NOT budget) -(asmr -mukbang)")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var slugs []string
+	for _, tag := range PositiveTags(node) {
+		slugs = append(slugs, tag.Slug)
+	}
+	// mukbang is negated twice, so a matching creator may carry it
+	if got, want := strings.Join(slugs, " "), "cooking vegan mukbang"; got != want {
+		t.Errorf("PositiveTags = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,179 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// maxMemoryEntries bounds the in-memory cache; expired entries are swept when it fills up
+const maxMemoryEntries = 10000
+
+// CacheStore persists cached lookups so they survive restarts and are shared
+// between instances. GetCached reports found=false for missing or expired entries.
+type CacheStore interface {
+	GetCached(ctx context.Context, key string) (value []byte, expiresAt time.Time, found bool, err error)
+	PutCached(ctx context.Context, key string, value []byte, expiresAt time.Time) error
+}
+
+type cacheEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// cachedClient serves channel lookups from an in-memory cache backed by a
+// CacheStore and forwards everything else to the wrapped client
+type cachedClient struct {
+	Client
+	ttl   time.Duration
+	store CacheStore
+
+	mu     sync.Mutex
+	memory map[string]cacheEntry
+}
+
+// NewCachedClient wraps client so channel lookups are cached for ttl.
+// store may be nil to cache in memory only.
+func NewCachedClient(client Client, ttl time.Duration, store CacheStore) Client {
+	return &cachedClient{
+		Client: client,
+		ttl:    ttl,
+		store:  store,
+		memory: map[string]cacheEntry{},
+	}
+}
+
+// ResolveChannel caches the channel ID a reference resolved to, plus the channel itself
+func (c *cachedClient) ResolveChannel(ctx context.Context, ref ChannelRef) (*Channel, error) {
+	refKey := "ref:" + ref.Kind.String() + ":" + ref.Value
+
+	var channelID string
+	if c.get(ctx, refKey, &channelID) {
+		var channel Channel
+		if c.get(ctx, channelKey(channelID), &channel) {
+			return &channel, nil
+		}
+	}
+
+	channel, err := c.Client.ResolveChannel(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(ctx, refKey, channel.ID)
+	c.put(ctx, channelKey(channel.ID), channel)
+	return channel, nil
+}
+
+// FetchChannel serves a channel from the cache when possible
+func (c *cachedClient) FetchChannel(ctx context.Context, channelID string) (*Channel, error) {
+	var channel Channel
+	if c.get(ctx, channelKey(channelID), &channel) {
+		return &channel, nil
+	}
+
+	fetched, err := c.Client.FetchChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(ctx, channelKey(fetched.ID), fetched)
+	return fetched, nil
+}
+
+// FetchChannels only calls the API for channels missing from the cache
+func (c *cachedClient) FetchChannels(ctx context.Context, channelIDs []string) ([]Channel, error) {
+	var channels []Channel
+	var missing []string
+	for _, id := range channelIDs {
+		var channel Channel
+		if c.get(ctx, channelKey(id), &channel) {
+			channels = append(channels, channel)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) == 0 {
+		return channels, nil
+	}
+
+	fetched, err := c.Client.FetchChannels(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fetched {
+		c.put(ctx, channelKey(fetched[i].ID), &fetched[i])
+	}
+
+	return append(channels, fetched...), nil
+}
+
+func channelKey(channelID string) string {
+	return "channel:" + channelID
+}
+
+// get looks a key up in memory, then in the store, decoding it into out
+func (c *cachedClient) get(ctx context.Context, key string, out interface{}) bool {
+	c.mu.Lock()
+	entry, ok := c.memory[key]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return json.Unmarshal(entry.value, out) == nil
+	}
+
+	if c.store == nil {
+		return false
+	}
+
+	value, expiresAt, found, err := c.store.GetCached(ctx, key)
+	if err != nil || !found {
+		return false
+	}
+	if json.Unmarshal(value, out) != nil {
+		return false
+	}
+
+	c.remember(key, cacheEntry{value: value, expiresAt: expiresAt})
+	return true
+}
+
+// put stores a value in memory and in the store. Store failures only cost a
+// future cache miss, so they are ignored.
+func (c *cachedClient) put(ctx context.Context, key string, value interface{}) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	expiresAt := time.Now().Add(c.ttl)
+
+	c.remember(key, cacheEntry{value: raw, expiresAt: expiresAt})
+
+	if c.store != nil {
+		c.store.PutCached(ctx, key, raw, expiresAt)
+	}
+}
+
+// remember adds an entry to the in-memory cache, sweeping expired entries
+// when it is full
+func (c *cachedClient) remember(key string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.memory) >= maxMemoryEntries {
+		now := time.Now()
+		for k, e := range c.memory {
+			if now.After(e.expiresAt) {
+				delete(c.memory, k)
+			}
+		}
+		// Still full of live entries: start over rather than grow without bound
+		if len(c.memory) >= maxMemoryEntries {
+			c.memory = map[string]cacheEntry{}
+		}
+	}
+
+	c.memory[key] = entry
+}
@@ -0,0 +1,305 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+)
+
+// DefaultBaseURL is the production YouTube Data API v3 endpoint
+const DefaultBaseURL = "https://www.googleapis.com/youtube/v3"
+
+// MaxBatchSize is the maximum number of IDs accepted by a single list call
+const MaxBatchSize = 50
+
+var (
+	// ErrNotFound is returned when the API has no channel or video for a lookup
+	ErrNotFound = errors.New("youtube: not found")
+	// ErrAmbiguous is returned when a lookup matches more than one channel
+	ErrAmbiguous = errors.New("youtube: ambiguous channel reference")
+)
+
+// Client is the subset of the YouTube Data API used by the service
+type Client interface {
+	// ResolveChannel returns the channel a parsed reference points at
+	ResolveChannel(ctx context.Context, ref ChannelRef) (*Channel, error)
+	// FetchChannel returns the details of a single channel
+	FetchChannel(ctx context.Context, channelID string) (*Channel, error)
+	// FetchChannels returns the details of up to MaxBatchSize channels in one call.
+	// Channels that were deleted or terminated are missing from the result.
+	FetchChannels(ctx context.Context, channelIDs []string) ([]Channel, error)
+	// FetchVideos returns up to max uploads from a channel's uploads playlist
+	// published after since, newest first
+	FetchVideos(ctx context.Context, uploadsPlaylistID string, since time.Time, max int) ([]Video, error)
+}
+
+// Channel holds the channel fields the service stores
+type Channel struct {
+	ID                string
+	Handle            string
+	Title             string
+	Description       string
+	ThumbnailURL      string
+	UploadsPlaylistID string
+	Country           string
+	Keywords          string
+	BannerURL         string
+	Statistics        ChannelStatistics
+}
+
+// ChannelStatistics holds the public counters of a channel
+type ChannelStatistics struct {
+	SubscriberCount       int64
+	HiddenSubscriberCount bool // SubscriberCount is zero when the owner hides it
+	ViewCount             int64
+	VideoCount            int64
+}
+
+var (
+	// API is the global client instance used by the handlers
+	API Client
+	// Uncached is API without the lookup cache, for callers that must see
+	// current channel data such as the refresher
+	Uncached Client
+	// Quota is the global ledger that API charges its calls to
+	Quota *Ledger
+)
+
+// InitClient creates the global clients and quota ledger from the loaded
+// configuration, persisting quota usage and cached lookups in the given stores
+func InitClient(usage UsageStore, cache CacheStore) {
+	cfg := config.AppConfig.YouTube
+	Quota = NewLedger(cfg.DailyQuota, usage)
+	Uncached = NewClient(cfg, Quota)
+	API = NewCachedClient(Uncached, cfg.CacheTTL, cache)
+}
+
+// NewClient creates a Client that talks to the YouTube Data API over HTTP.
+// Every call is charged to ledger first; a nil ledger means no quota accounting.
+func NewClient(cfg config.YouTubeConfig, ledger *Ledger) Client {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &httpClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  cfg.APIKey,
+		http:    &http.Client{Timeout: 10 * time.Second},
+		ledger:  ledger,
+	}
+}
+
+type httpClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+	ledger  *Ledger
+}
+
+// apiError is the error envelope returned by Google APIs
+type apiError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type thumbnails struct {
+	Default struct {
+		URL string `json:"url"`
+	} `json:"default"`
+	High struct {
+		URL string `json:"url"`
+	} `json:"high"`
+}
+
+func (t thumbnails) best() string {
+	if t.High.URL != "" {
+		return t.High.URL
+	}
+	return t.Default.URL
+}
+
+// get performs a GET request against an API endpoint and decodes the JSON response
+func (c *httpClient) get(ctx context.Context, endpoint string, params url.Values, out interface{}) error {
+	if c.ledger != nil {
+		if err := c.ledger.Charge(ctx, endpoint); err != nil {
+			return err
+		}
+	}
+
+	params.Set("key", c.apiKey)
+	reqURL := fmt.Sprintf("%s/%s?%s", c.baseURL, endpoint, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %w", endpoint, err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiError
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("%s returned %d: %s", endpoint, resp.StatusCode, apiErr.Error.Message)
+		}
+		return fmt.Errorf("%s returned %d", endpoint, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", endpoint, err)
+	}
+
+	return nil
+}
+
+// channelListResponse is the subset of a channels.list response the client reads
+type channelListResponse struct {
+	Items []struct {
+		ID      string `json:"id"`
+		Snippet struct {
+			Title       string     `json:"title"`
+			Description string     `json:"description"`
+			CustomURL   string     `json:"customUrl"`
+			Thumbnails  thumbnails `json:"thumbnails"`
+		} `json:"snippet"`
+		ContentDetails struct {
+			RelatedPlaylists struct {
+				Uploads string `json:"uploads"`
+			} `json:"relatedPlaylists"`
+		} `json:"contentDetails"`
+		Statistics struct {
+			ViewCount             int64 `json:"viewCount,string"`
+			SubscriberCount       int64 `json:"subscriberCount,string"`
+			HiddenSubscriberCount bool  `json:"hiddenSubscriberCount"`
+			VideoCount            int64 `json:"videoCount,string"`
+		} `json:"statistics"`
+		BrandingSettings struct {
+			Channel struct {
+				Keywords string `json:"keywords"`
+				Country  string `json:"country"`
+			} `json:"channel"`
+			Image struct {
+				BannerExternalURL string `json:"bannerExternalUrl"`
+			} `json:"image"`
+		} `json:"brandingSettings"`
+	} `json:"items"`
+}
+
+// listChannels calls channels.list filtered by a single lookup parameter
+func (c *httpClient) listChannels(ctx context.Context, filter, value string) ([]Channel, error) {
+	params := url.Values{}
+	params.Set("part", "snippet,contentDetails,statistics,brandingSettings")
+	params.Set(filter, value)
+
+	var result channelListResponse
+	if err := c.get(ctx, "channels", params, &result); err != nil {
+		return nil, err
+	}
+
+	channels := make([]Channel, 0, len(result.Items))
+	for _, item := range result.Items {
+		channels = append(channels, Channel{
+			ID:                item.ID,
+			Handle:            item.Snippet.CustomURL,
+			Title:             item.Snippet.Title,
+			Description:       item.Snippet.Description,
+			ThumbnailURL:      item.Snippet.Thumbnails.best(),
+			UploadsPlaylistID: item.ContentDetails.RelatedPlaylists.Uploads,
+			Country:           item.BrandingSettings.Channel.Country,
+			Keywords:          item.BrandingSettings.Channel.Keywords,
+			BannerURL:         item.BrandingSettings.Image.BannerExternalURL,
+			Statistics: ChannelStatistics{
+				SubscriberCount:       item.Statistics.SubscriberCount,
+				HiddenSubscriberCount: item.Statistics.HiddenSubscriberCount,
+				ViewCount:             item.Statistics.ViewCount,
+				VideoCount:            item.Statistics.VideoCount,
+			},
+		})
+	}
+
+	return channels, nil
+}
+
+// lookupChannel calls channels.list and expects at most one matching channel
+func (c *httpClient) lookupChannel(ctx context.Context, filter, value string) (*Channel, error) {
+	channels, err := c.listChannels(ctx, filter, value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(channels) {
+	case 0:
+		return nil, fmt.Errorf("no channel for %s=%s: %w", filter, value, ErrNotFound)
+	case 1:
+		return &channels[0], nil
+	default:
+		return nil, fmt.Errorf("%d channels for %s=%s: %w", len(channels), filter, value, ErrAmbiguous)
+	}
+}
+
+// ResolveChannel looks up a channel with the channels endpoint, which costs
+// one quota unit per call. Legacy /c/ names have no exact lookup, so they are
+// tried as a handle and as a username and must agree on a single channel.
+func (c *httpClient) ResolveChannel(ctx context.Context, ref ChannelRef) (*Channel, error) {
+	switch ref.Kind {
+	case RefHandle:
+		return c.lookupChannel(ctx, "forHandle", ref.Value)
+	case RefChannelID:
+		return c.lookupChannel(ctx, "id", ref.Value)
+	case RefUsername:
+		return c.lookupChannel(ctx, "forUsername", ref.Value)
+	case RefCustomURL:
+		byHandle, err := c.lookupChannel(ctx, "forHandle", ref.Value)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		byUsername, err := c.lookupChannel(ctx, "forUsername", ref.Value)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+
+		switch {
+		case byHandle == nil && byUsername == nil:
+			return nil, fmt.Errorf("no channel for custom URL %s: %w", ref.Value, ErrNotFound)
+		case byHandle == nil:
+			return byUsername, nil
+		case byUsername == nil || byUsername.ID == byHandle.ID:
+			return byHandle, nil
+		default:
+			return nil, fmt.Errorf("custom URL %s matches handle of %s and username of %s: %w",
+				ref.Value, byHandle.ID, byUsername.ID, ErrAmbiguous)
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported reference kind %d: %w", ref.Kind, ErrInvalidRef)
+}
+
+// FetchChannel fetches the snippet, content details, statistics and branding of a channel
+func (c *httpClient) FetchChannel(ctx context.Context, channelID string) (*Channel, error) {
+	return c.lookupChannel(ctx, "id", channelID)
+}
+
+// FetchChannels fetches up to MaxBatchSize channels with a single channels.list call
+func (c *httpClient) FetchChannels(ctx context.Context, channelIDs []string) ([]Channel, error) {
+	if len(channelIDs) > MaxBatchSize {
+		return nil, fmt.Errorf("cannot fetch %d channels in one call, maximum is %d", len(channelIDs), MaxBatchSize)
+	}
+	if len(channelIDs) == 0 {
+		return nil, nil
+	}
+
+	return c.listChannels(ctx, "id", strings.Join(channelIDs, ","))
+}
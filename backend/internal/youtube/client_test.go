@@ -0,0 +1,163 @@
+package youtube_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube/youtubetest"
+)
+
+const (
+	mkbhdID      = "UCBJycsmduvYEL83R_U4JriQ"
+	veritasiumID = "UCHnyfMqiRRG1u-2MsSQLbXA"
+)
+
+func newTestClient(t *testing.T) youtube.Client {
+	t.Helper()
+	server := youtubetest.NewServer()
+	t.Cleanup(server.Close)
+	return youtube.NewClient(youtubetest.Config(server), nil)
+}
+
+func TestResolveChannel(t *testing.T) {
+	client := newTestClient(t)
+
+	tests := []struct {
+		input   string
+		wantID  string
+		wantErr error
+	}{
+		{input: "@mkbhd", wantID: mkbhdID},
+		{input: "https://www.youtube.com/@veritasium", wantID: veritasiumID},
+		{input: "youtube.com/channel/" + mkbhdID, wantID: mkbhdID},
+		{input: "https://www.youtube.com/user/marquesbrownlee", wantID: mkbhdID},
+		// Only the username exists, so the custom URL resolves through it
+		{input: "https://www.youtube.com/c/marquesbrownlee", wantID: mkbhdID},
+		{input: "@nosuchchannel", wantErr: youtube.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ref, err := youtube.ParseChannelRef(tt.input)
+			if err != nil {
+				t.Fatalf("ParseChannelRef(%q): %v", tt.input, err)
+			}
+
+			channel, err := client.ResolveChannel(context.Background(), ref)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveChannel: %v", err)
+			}
+			if channel.ID != tt.wantID {
+				t.Errorf("got channel %s, want %s", channel.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestFetchChannel(t *testing.T) {
+	channel, err := newTestClient(t).FetchChannel(context.Background(), mkbhdID)
+	if err != nil {
+		t.Fatalf("FetchChannel: %v", err)
+	}
+
+	want := youtube.Channel{
+		ID:                mkbhdID,
+		Handle:            "@mkbhd",
+		Title:             "Marques Brownlee",
+		Description:       "MKBHD: Quality Tech Videos | YouTuber | Geek | Consumer Electronics | Tech Head | Internet Personality!",
+		ThumbnailURL:      "https://yt3.ggpht.com/mkbhd-high=s800-c-k-c0x00ffffff-no-rj",
+		UploadsPlaylistID: "UUBJycsmduvYEL83R_U4JriQ",
+		Country:           "US",
+		Keywords:          `MKBHD tech "consumer electronics" smartphones reviews`,
+		BannerURL:         "https://yt3.googleusercontent.com/mkbhd-banner",
+		Statistics: youtube.ChannelStatistics{
+			SubscriberCount: 19600000,
+			ViewCount:       4507312117,
+			VideoCount:      1712,
+		},
+	}
+	if *channel != want {
+		t.Errorf("got %+v, want %+v", *channel, want)
+	}
+}
+
+func TestFetchChannelsSkipsMissing(t *testing.T) {
+	channels, err := newTestClient(t).FetchChannels(context.Background(),
+		[]string{mkbhdID, "UC0000000000000000000000", veritasiumID})
+	if err != nil {
+		t.Fatalf("FetchChannels: %v", err)
+	}
+
+	var ids []string
+	for _, c := range channels {
+		ids = append(ids, c.ID)
+	}
+	if len(ids) != 2 || ids[0] != mkbhdID || ids[1] != veritasiumID {
+		t.Errorf("got channels %v, want [%s %s]", ids, mkbhdID, veritasiumID)
+	}
+}
+
+func TestFetchVideos(t *testing.T) {
+	tests := []struct {
+		name  string
+		since time.Time
+		max   int
+		want  []string
+	}{
+		{name: "all pages", max: 50, want: []string{"aB3dE5gH7jK", "zY9xW7vU5tS", "qR8sT2uV4wX"}},
+		{name: "stops at max", max: 2, want: []string{"aB3dE5gH7jK", "zY9xW7vU5tS"}},
+		{
+			name:  "stops at since",
+			since: time.Date(2025, 1, 7, 16, 30, 0, 0, time.UTC),
+			max:   50,
+			want:  []string{"aB3dE5gH7jK"},
+		},
+	}
+
+	client := newTestClient(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			videos, err := client.FetchVideos(context.Background(), "UUBJycsmduvYEL83R_U4JriQ", tt.since, tt.max)
+			if err != nil {
+				t.Fatalf("FetchVideos: %v", err)
+			}
+
+			var ids []string
+			for _, v := range videos {
+				ids = append(ids, v.ID)
+				if v.Duration == 0 {
+					t.Errorf("video %s has no duration", v.ID)
+				}
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("got videos %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("got videos %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMissingAPIKey(t *testing.T) {
+	server := youtubetest.NewServer()
+	defer server.Close()
+
+	cfg := youtubetest.Config(server)
+	cfg.APIKey = ""
+	_, err := youtube.NewClient(cfg, nil).FetchChannel(context.Background(), mkbhdID)
+	if err == nil {
+		t.Fatal("expected an error without an API key")
+	}
+}
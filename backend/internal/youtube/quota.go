@@ -0,0 +1,172 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// endpointCosts are the documented quota costs of the endpoints the client calls
+var endpointCosts = map[string]int{
+	"channels":      1,
+	"playlistItems": 1,
+	"videos":        1,
+	"search":        100,
+}
+
+// ErrQuotaExceeded is returned instead of calling the API once the daily budget is spent
+var ErrQuotaExceeded = errors.New("youtube: daily quota budget spent")
+
+// QuotaExceededError reports when the quota budget becomes available again
+type QuotaExceededError struct {
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%v, resets in %s", ErrQuotaExceeded, e.RetryAfter.Round(time.Second))
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// quotaLocation is the time zone in which YouTube resets daily quotas
+var quotaLocation = loadQuotaLocation()
+
+func loadQuotaLocation() *time.Location {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		return time.FixedZone("PST", -8*60*60)
+	}
+	return loc
+}
+
+// QuotaDay returns the YouTube quota day that t falls in, as YYYY-MM-DD
+func QuotaDay(t time.Time) string {
+	return t.In(quotaLocation).Format("2006-01-02")
+}
+
+// nextQuotaReset returns the next midnight Pacific time after t
+func nextQuotaReset(t time.Time) time.Time {
+	local := t.In(quotaLocation)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, quotaLocation)
+}
+
+// UsageStore persists quota usage so a restart does not reset the ledger
+type UsageStore interface {
+	AddQuotaUsage(ctx context.Context, day, endpoint string, units int) error
+	QuotaUsage(ctx context.Context, day string) (map[string]int, error)
+}
+
+// Usage is a snapshot of the ledger for the current quota day
+type Usage struct {
+	Day        string         `json:"day"`
+	Budget     int            `json:"budget"`
+	Spent      int            `json:"spent"`
+	Remaining  int            `json:"remaining"`
+	ByEndpoint map[string]int `json:"by_endpoint"`
+	ResetsAt   time.Time      `json:"resets_at"`
+}
+
+// Ledger charges API calls against a daily quota budget
+type Ledger struct {
+	mu         sync.Mutex
+	budget     int
+	store      UsageStore
+	day        string
+	spent      int
+	byEndpoint map[string]int
+}
+
+// NewLedger creates a ledger with a daily budget in quota units. A budget of
+// zero or less is unlimited. store may be nil to keep usage in memory only.
+func NewLedger(budget int, store UsageStore) *Ledger {
+	return &Ledger{budget: budget, store: store}
+}
+
+// Charge reserves the cost of one call to endpoint, returning a
+// *QuotaExceededError when the remaining budget cannot cover it
+func (l *Ledger) Charge(ctx context.Context, endpoint string) error {
+	cost, ok := endpointCosts[endpoint]
+	if !ok {
+		return fmt.Errorf("no quota cost known for endpoint %s", endpoint)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.rollover(ctx, now)
+
+	if l.budget > 0 && l.spent+cost > l.budget {
+		return &QuotaExceededError{RetryAfter: nextQuotaReset(now).Sub(now)}
+	}
+
+	// Persist first, so a failing store refuses calls without spending budget
+	if l.store != nil {
+		if err := l.store.AddQuotaUsage(ctx, l.day, endpoint, cost); err != nil {
+			return fmt.Errorf("failed to persist quota usage: %w", err)
+		}
+	}
+
+	l.spent += cost
+	l.byEndpoint[endpoint] += cost
+	return nil
+}
+
+// Usage returns the spend of the current quota day
+func (l *Ledger) Usage(ctx context.Context) Usage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.rollover(ctx, now)
+
+	byEndpoint := make(map[string]int, len(l.byEndpoint))
+	for endpoint, units := range l.byEndpoint {
+		byEndpoint[endpoint] = units
+	}
+
+	remaining := -1 // Unlimited
+	if l.budget > 0 {
+		remaining = max(0, l.budget-l.spent)
+	}
+
+	return Usage{
+		Day:        l.day,
+		Budget:     l.budget,
+		Spent:      l.spent,
+		Remaining:  remaining,
+		ByEndpoint: byEndpoint,
+		ResetsAt:   nextQuotaReset(now),
+	}
+}
+
+// rollover starts a new quota day when needed, loading any usage already
+// persisted for it. Callers must hold l.mu.
+func (l *Ledger) rollover(ctx context.Context, now time.Time) {
+	today := QuotaDay(now)
+	if today == l.day {
+		return
+	}
+
+	l.day = today
+	l.spent = 0
+	l.byEndpoint = map[string]int{}
+
+	if l.store == nil {
+		return
+	}
+
+	persisted, err := l.store.QuotaUsage(ctx, today)
+	if err != nil {
+		// Start from zero rather than refusing all calls; the next day retries
+		return
+	}
+	for endpoint, units := range persisted {
+		l.byEndpoint[endpoint] = units
+		l.spent += units
+	}
+}
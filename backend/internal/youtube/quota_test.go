@@ -0,0 +1,77 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// memoryStore is a UsageStore that can be made to fail
+type memoryStore struct {
+	usage map[string]map[string]int
+	fail  bool
+}
+
+func (s *memoryStore) AddQuotaUsage(ctx context.Context, day, endpoint string, units int) error {
+	if s.fail {
+		return errors.New("database unavailable")
+	}
+	if s.usage[day] == nil {
+		s.usage[day] = map[string]int{}
+	}
+	s.usage[day][endpoint] += units
+	return nil
+}
+
+func (s *memoryStore) QuotaUsage(ctx context.Context, day string) (map[string]int, error) {
+	return s.usage[day], nil
+}
+
+func TestChargeStopsAtBudget(t *testing.T) {
+	ctx := context.Background()
+	ledger := NewLedger(2, nil)
+
+	for i := 0; i < 2; i++ {
+		if err := ledger.Charge(ctx, "channels"); err != nil {
+			t.Fatalf("charge %d: %v", i+1, err)
+		}
+	}
+
+	err := ledger.Charge(ctx, "channels")
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("got error %v, want a *QuotaExceededError", err)
+	}
+	if quotaErr.RetryAfter <= 0 {
+		t.Errorf("got RetryAfter %s, want it positive", quotaErr.RetryAfter)
+	}
+	if usage := ledger.Usage(ctx); usage.Spent != 2 || usage.Remaining != 0 {
+		t.Errorf("got spent %d, remaining %d, want 2 and 0", usage.Spent, usage.Remaining)
+	}
+}
+
+func TestChargeFailingStoreSpendsNothing(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{usage: map[string]map[string]int{}}
+	ledger := NewLedger(100, store)
+
+	store.fail = true
+	for i := 0; i < 3; i++ {
+		if err := ledger.Charge(ctx, "search"); err == nil {
+			t.Fatal("expected an error while the store is failing")
+		}
+	}
+	if usage := ledger.Usage(ctx); usage.Spent != 0 || usage.ByEndpoint["search"] != 0 {
+		t.Errorf("got spent %d (%v) after failed charges, want 0", usage.Spent, usage.ByEndpoint)
+	}
+
+	// The budget is still whole once the store recovers
+	store.fail = false
+	if err := ledger.Charge(ctx, "search"); err != nil {
+		t.Fatalf("Charge after recovery: %v", err)
+	}
+	usage := ledger.Usage(ctx)
+	if usage.Spent != 100 || store.usage[usage.Day]["search"] != 100 {
+		t.Errorf("got spent %d, persisted %d, want 100 and 100", usage.Spent, store.usage[usage.Day]["search"])
+	}
+}
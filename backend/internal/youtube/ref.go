@@ -0,0 +1,164 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RefKind identifies how a channel was referenced by the user
+type RefKind int
+
+const (
+	// RefHandle is an @handle, e.g. "@mkbhd" or "youtube.com/@mkbhd"
+	RefHandle RefKind = iota
+	// RefChannelID is a raw channel ID, e.g. "UCBJycsmduvYEL83R_U4JriQ"
+	RefChannelID
+	// RefUsername is a legacy username from a youtube.com/user/ URL
+	RefUsername
+	// RefCustomURL is a legacy custom name from a youtube.com/c/ URL
+	RefCustomURL
+)
+
+func (k RefKind) String() string {
+	switch k {
+	case RefHandle:
+		return "handle"
+	case RefChannelID:
+		return "channel ID"
+	case RefUsername:
+		return "username"
+	case RefCustomURL:
+		return "custom URL"
+	default:
+		return "unknown"
+	}
+}
+
+// ChannelRef is a parsed reference to a channel
+type ChannelRef struct {
+	Kind  RefKind
+	Value string // Handle without '@', channel ID, username or custom name
+}
+
+func (r ChannelRef) String() string {
+	if r.Kind == RefHandle {
+		return "@" + r.Value
+	}
+	return fmt.Sprintf("%s %s", r.Kind, r.Value)
+}
+
+// ErrInvalidRef is returned when input is not a recognizable channel reference
+var ErrInvalidRef = errors.New("youtube: unrecognized channel reference")
+
+// RefError explains why input is not a channel reference
+type RefError struct {
+	Reason string
+}
+
+func (e *RefError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Reason, ErrInvalidRef)
+}
+
+func (e *RefError) Unwrap() error {
+	return ErrInvalidRef
+}
+
+func invalidRef(format string, args ...interface{}) error {
+	return &RefError{Reason: fmt.Sprintf(format, args...)}
+}
+
+var (
+	channelIDPattern = regexp.MustCompile(`^UC[0-9A-Za-z_-]{22}$`)
+	handlePattern    = regexp.MustCompile(`^[0-9A-Za-z_.-]{3,30}$`)
+	namePattern      = regexp.MustCompile(`^[0-9A-Za-z_.-]{1,100}$`)
+)
+
+var youtubeHosts = map[string]bool{
+	"youtube.com":     true,
+	"www.youtube.com": true,
+	"m.youtube.com":   true,
+}
+
+// ParseChannelRef parses an @handle, a bare channel ID, or a youtube.com
+// channel URL (/@handle, /channel/ID, /c/name or /user/name).
+// A bare word without '@' is treated as a handle.
+func ParseChannelRef(input string) (ChannelRef, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return ChannelRef{}, invalidRef("empty input")
+	}
+
+	if isURL(input) {
+		return parseChannelURL(input)
+	}
+
+	if channelIDPattern.MatchString(input) {
+		return ChannelRef{Kind: RefChannelID, Value: input}, nil
+	}
+
+	return parseHandle(input)
+}
+
+func isURL(input string) bool {
+	lower := strings.ToLower(input)
+	return strings.HasPrefix(lower, "http://") ||
+		strings.HasPrefix(lower, "https://") ||
+		strings.Contains(lower, "youtube.com/")
+}
+
+func parseHandle(input string) (ChannelRef, error) {
+	handle := strings.TrimPrefix(input, "@")
+	if !handlePattern.MatchString(handle) {
+		return ChannelRef{}, invalidRef("invalid handle %q", input)
+	}
+	return ChannelRef{Kind: RefHandle, Value: handle}, nil
+}
+
+func parseChannelURL(input string) (ChannelRef, error) {
+	if !strings.Contains(input, "://") {
+		input = "https://" + input
+	}
+
+	u, err := url.Parse(input)
+	if err != nil {
+		return ChannelRef{}, invalidRef("invalid URL %q", input)
+	}
+	if !youtubeHosts[strings.ToLower(u.Hostname())] {
+		return ChannelRef{}, invalidRef("%q is not a youtube.com URL", input)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	first := segments[0]
+
+	if strings.HasPrefix(first, "@") {
+		return parseHandle(first)
+	}
+
+	if len(segments) < 2 {
+		return ChannelRef{}, invalidRef("URL %q does not point at a channel", input)
+	}
+	value := segments[1]
+
+	switch first {
+	case "channel":
+		if !channelIDPattern.MatchString(value) {
+			return ChannelRef{}, invalidRef("invalid channel ID %q", value)
+		}
+		return ChannelRef{Kind: RefChannelID, Value: value}, nil
+	case "user":
+		if !namePattern.MatchString(value) {
+			return ChannelRef{}, invalidRef("invalid username %q", value)
+		}
+		return ChannelRef{Kind: RefUsername, Value: value}, nil
+	case "c":
+		if !namePattern.MatchString(value) {
+			return ChannelRef{}, invalidRef("invalid custom URL %q", value)
+		}
+		return ChannelRef{Kind: RefCustomURL, Value: value}, nil
+	}
+
+	return ChannelRef{}, invalidRef("URL %q does not point at a channel", input)
+}
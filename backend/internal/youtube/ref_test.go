@@ -0,0 +1,83 @@
+package youtube_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/internal/youtube"
+)
+
+func TestParseChannelRef(t *testing.T) {
+	tests := []struct {
+		input string
+		want  youtube.ChannelRef
+	}{
+		{input: "@mkbhd", want: youtube.ChannelRef{Kind: youtube.RefHandle, Value: "mkbhd"}},
+		{input: "  mkbhd ", want: youtube.ChannelRef{Kind: youtube.RefHandle, Value: "mkbhd"}},
+		{input: mkbhdID, want: youtube.ChannelRef{Kind: youtube.RefChannelID, Value: mkbhdID}},
+		{input: "m.youtube.com/@mkbhd/videos", want: youtube.ChannelRef{Kind: youtube.RefHandle, Value: "mkbhd"}},
+		{input: "https://www.youtube.com/channel/" + mkbhdID, want: youtube.ChannelRef{Kind: youtube.RefChannelID, Value: mkbhdID}},
+		{input: "http://youtube.com/user/marquesbrownlee", want: youtube.ChannelRef{Kind: youtube.RefUsername, Value: "marquesbrownlee"}},
+		{input: "https://www.youtube.com/c/marquesbrownlee", want: youtube.ChannelRef{Kind: youtube.RefCustomURL, Value: "marquesbrownlee"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := youtube.ParseChannelRef(tt.input)
+			if err != nil {
+				t.Fatalf("ParseChannelRef(%q): %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseChannelRef(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChannelRefErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantReason string
+	}{
+		{name: "empty", input: "  ", wantReason: "empty input"},
+		{
+			name:       "not YouTube",
+			input:      "https://vimeo.com/@mkbhd",
+			wantReason: `"https://vimeo.com/@mkbhd" is not a youtube.com URL`,
+		},
+		{
+			name:       "video URL",
+			input:      "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			wantReason: `URL "https://www.youtube.com/watch?v=dQw4w9WgXcQ" does not point at a channel`,
+		},
+		{
+			name:       "channel ID too short",
+			input:      "https://www.youtube.com/channel/UCBJycsmduvYEL83R_U4Jri",
+			wantReason: `invalid channel ID "UCBJycsmduvYEL83R_U4Jri"`,
+		},
+		{name: "bare @", input: "@", wantReason: `invalid handle "@"`},
+		{
+			name:       "custom URL without a name",
+			input:      "youtube.com/c/",
+			wantReason: `URL "https://youtube.com/c/" does not point at a channel`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := youtube.ParseChannelRef(tt.input)
+
+			var refErr *youtube.RefError
+			if !errors.As(err, &refErr) {
+				t.Fatalf("ParseChannelRef(%q) error = %v, want a *RefError", tt.input, err)
+			}
+			if refErr.Reason != tt.wantReason {
+				t.Errorf("ParseChannelRef(%q) reason = %s, want %s", tt.input, refErr.Reason, tt.wantReason)
+			}
+			if !errors.Is(err, youtube.ErrInvalidRef) {
+				t.Errorf("ParseChannelRef(%q) error does not wrap ErrInvalidRef", tt.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Video holds the fields of a single upload
+type Video struct {
+	ID           string
+	Title        string
+	PublishedAt  time.Time
+	Duration     time.Duration
+	ThumbnailURL string
+}
+
+// FetchVideos pages through an uploads playlist, newest first, until it reaches
+// a video published at or before since or has collected max videos. Durations
+// are then filled in with videos.list calls of up to MaxBatchSize IDs.
+func (c *httpClient) FetchVideos(ctx context.Context, uploadsPlaylistID string, since time.Time, max int) ([]Video, error) {
+	var videos []Video
+	pageToken := ""
+
+	for len(videos) < max {
+		params := url.Values{}
+		params.Set("part", "snippet")
+		params.Set("playlistId", uploadsPlaylistID)
+		params.Set("maxResults", strconv.Itoa(MaxBatchSize))
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+
+		var result struct {
+			NextPageToken string `json:"nextPageToken"`
+			Items         []struct {
+				Snippet struct {
+					Title       string     `json:"title"`
+					PublishedAt time.Time  `json:"publishedAt"`
+					Thumbnails  thumbnails `json:"thumbnails"`
+					ResourceID  struct {
+						VideoID string `json:"videoId"`
+					} `json:"resourceId"`
+				} `json:"snippet"`
+			} `json:"items"`
+		}
+		if err := c.get(ctx, "playlistItems", params, &result); err != nil {
+			return nil, err
+		}
+
+		reachedSince := false
+		for _, item := range result.Items {
+			if !item.Snippet.PublishedAt.After(since) {
+				reachedSince = true
+				break
+			}
+			videos = append(videos, Video{
+				ID:           item.Snippet.ResourceID.VideoID,
+				Title:        item.Snippet.Title,
+				PublishedAt:  item.Snippet.PublishedAt,
+				ThumbnailURL: item.Snippet.Thumbnails.best(),
+			})
+			if len(videos) == max {
+				break
+			}
+		}
+
+		if reachedSince || result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	if err := c.fillDurations(ctx, videos); err != nil {
+		return nil, err
+	}
+
+	return videos, nil
+}
+
+// fillDurations looks up the duration of each video in batches
+func (c *httpClient) fillDurations(ctx context.Context, videos []Video) error {
+	for start := 0; start < len(videos); start += MaxBatchSize {
+		end := min(start+MaxBatchSize, len(videos))
+
+		ids := make([]string, 0, end-start)
+		for _, v := range videos[start:end] {
+			ids = append(ids, v.ID)
+		}
+
+		params := url.Values{}
+		params.Set("part", "contentDetails")
+		params.Set("id", strings.Join(ids, ","))
+
+		var result struct {
+			Items []struct {
+				ID             string `json:"id"`
+				ContentDetails struct {
+					Duration string `json:"duration"`
+				} `json:"contentDetails"`
+			} `json:"items"`
+		}
+		if err := c.get(ctx, "videos", params, &result); err != nil {
+			return err
+		}
+
+		durations := make(map[string]time.Duration, len(result.Items))
+		for _, item := range result.Items {
+			d, err := parseDuration(item.ContentDetails.Duration)
+			if err != nil {
+				return fmt.Errorf("video %s: %w", item.ID, err)
+			}
+			durations[item.ID] = d
+		}
+
+		for i := start; i < end; i++ {
+			videos[i].Duration = durations[videos[i].ID]
+		}
+	}
+
+	return nil
+}
+
+var durationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses the ISO 8601 durations used by the API, e.g. "PT1H2M3S".
+// Live streams that have not ended report "P0D".
+func parseDuration(s string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		d += time.Duration(n) * unit
+	}
+
+	return d, nil
+}
@@ -0,0 +1,129 @@
+// Package youtubetest provides a fake YouTube Data API server that replays
+// recorded responses, so code using the youtube package can run without network.
+package youtubetest
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+
+	"github.com/MichaelWaters001/youtube-recommender/backend/pkg/config"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// lookupParams lists, per endpoint, the query parameters that identify a fixture.
+// The first parameter present in a request selects the fixture file.
+var lookupParams = map[string][]string{
+	"search":        {"q"},
+	"channels":      {"forHandle", "id", "forUsername"},
+	"playlistItems": {"playlistId"},
+	"videos":        {"id"},
+}
+
+// emptyResponse is what the real API returns when nothing matches a lookup
+const emptyResponse = `{"kind": "youtube#listResponse", "pageInfo": {"totalResults": 0, "resultsPerPage": 0}, "items": []}`
+
+// NewServer starts a fake API server backed by the bundled fixtures
+func NewServer() *httptest.Server {
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return NewServerFS(sub)
+}
+
+// NewServerFS starts a fake API server backed by fixtures in fsys.
+//
+// A request to /<endpoint>?<param>=<value> is answered with the file
+// <endpoint>/<param>=<value>.json; a pageToken is appended as
+// _pageToken=<token>. Comma-separated id lookups merge the single-ID fixtures.
+// Unknown lookups get an empty item list, like the real API.
+func NewServerFS(fsys fs.FS) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		query := r.URL.Query()
+		if query.Get("key") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": {"code": 403, "message": "The request is missing a valid API key."}}`))
+			return
+		}
+
+		endpoint := path.Base(r.URL.Path)
+		params, ok := lookupParams[endpoint]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "message": "Not Found"}}`))
+			return
+		}
+
+		for _, param := range params {
+			value := query.Get(param)
+			if value == "" {
+				continue
+			}
+
+			if param == "id" && strings.Contains(value, ",") {
+				w.Write(mergeFixtures(fsys, endpoint, strings.Split(value, ",")))
+				return
+			}
+
+			name := param + "=" + strings.TrimPrefix(value, "@")
+			if token := query.Get("pageToken"); token != "" {
+				name += "_pageToken=" + token
+			}
+
+			body, err := fs.ReadFile(fsys, path.Join(endpoint, name+".json"))
+			if err != nil {
+				break
+			}
+			w.Write(body)
+			return
+		}
+
+		w.Write([]byte(emptyResponse))
+	}))
+}
+
+// Config returns a YouTube configuration pointing at the fake server
+func Config(server *httptest.Server) config.YouTubeConfig {
+	return config.YouTubeConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	}
+}
+
+// mergeFixtures answers a multi-ID lookup by combining the items of the
+// single-ID fixtures, skipping IDs that have none
+func mergeFixtures(fsys fs.FS, endpoint string, ids []string) []byte {
+	response := map[string]interface{}{"kind": "youtube#listResponse"}
+
+	items := []interface{}{}
+	for _, id := range ids {
+		body, err := fs.ReadFile(fsys, path.Join(endpoint, "id="+id+".json"))
+		if err != nil {
+			continue
+		}
+		var fixture struct {
+			Kind  string        `json:"kind"`
+			Items []interface{} `json:"items"`
+		}
+		if err := json.Unmarshal(body, &fixture); err != nil {
+			continue
+		}
+		response["kind"] = fixture.Kind
+		items = append(items, fixture.Items...)
+	}
+
+	response["items"] = items
+	response["pageInfo"] = map[string]int{"totalResults": len(items), "resultsPerPage": len(items)}
+
+	body, _ := json.Marshal(response)
+	return body
+}
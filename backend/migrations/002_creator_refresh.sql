@@ -0,0 +1,8 @@
+-- Track channel metadata refreshes
+ALTER TABLE creators
+    ADD COLUMN thumbnail_url TEXT,
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'unavailable')),
+    ADD COLUMN last_refreshed_at TIMESTAMPTZ;
+
+-- Lets the refresher find creators that are due for a refresh
+CREATE INDEX idx_creators_last_refreshed_at ON creators(last_refreshed_at);
@@ -0,0 +1,17 @@
+-- Channel branding details
+ALTER TABLE creators
+    ADD COLUMN country TEXT,
+    ADD COLUMN keywords TEXT,
+    ADD COLUMN banner_url TEXT;
+
+-- Time series of channel statistics, one row per fetch
+CREATE TABLE creator_stats (
+    id BIGSERIAL PRIMARY KEY,
+    creator_id INT NOT NULL REFERENCES creators(id) ON DELETE CASCADE,
+    captured_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    subscriber_count BIGINT, -- NULL when the channel hides its subscriber count
+    view_count BIGINT NOT NULL,
+    video_count BIGINT NOT NULL
+);
+
+CREATE INDEX idx_creator_stats_creator_captured ON creator_stats(creator_id, captured_at);
@@ -0,0 +1,16 @@
+-- Playlist that lists a channel's uploads
+ALTER TABLE creators ADD COLUMN uploads_playlist_id TEXT;
+
+-- Create videos table (recent uploads per creator)
+CREATE TABLE videos (
+    id SERIAL PRIMARY KEY,
+    creator_id INT NOT NULL REFERENCES creators(id) ON DELETE CASCADE,
+    youtube_video_id TEXT UNIQUE NOT NULL,
+    title TEXT NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL,
+    duration_seconds INT NOT NULL DEFAULT 0,
+    thumbnail_url TEXT
+);
+
+-- Newest-first listing and incremental sync per creator
+CREATE INDEX idx_videos_creator_published ON videos(creator_id, published_at DESC, id DESC);
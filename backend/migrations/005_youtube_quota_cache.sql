@@ -0,0 +1,18 @@
+-- YouTube API quota spent per quota day (Pacific time) and endpoint
+CREATE TABLE youtube_quota_usage (
+    day DATE NOT NULL,
+    endpoint TEXT NOT NULL,
+    units INT NOT NULL DEFAULT 0,
+    calls INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, endpoint)
+);
+
+-- Cached YouTube channel lookups
+CREATE TABLE youtube_cache (
+    key TEXT PRIMARY KEY,
+    value JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- User roles for admin and moderator endpoints
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin'));
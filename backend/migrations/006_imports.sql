@@ -0,0 +1,23 @@
+-- Bulk creator import jobs
+CREATE TABLE imports (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    format TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+-- One row per channel in an import file
+CREATE TABLE import_rows (
+    id SERIAL PRIMARY KEY,
+    import_id INT NOT NULL REFERENCES imports(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    input TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'added', 'existing', 'not_found', 'failed')),
+    creator_id INT REFERENCES creators(id) ON DELETE SET NULL,
+    error TEXT,
+    UNIQUE (import_id, row_number)
+);
+
+CREATE INDEX idx_imports_status ON imports(status);
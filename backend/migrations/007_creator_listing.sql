@@ -0,0 +1,21 @@
+-- Columns used to sort the creator listing
+ALTER TABLE creators
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN subscriber_count BIGINT;
+
+-- Backfill the latest known subscriber count
+UPDATE creators c
+SET subscriber_count = latest.subscriber_count
+FROM (
+    SELECT DISTINCT ON (creator_id) creator_id, subscriber_count
+    FROM creator_stats
+    ORDER BY creator_id, captured_at DESC
+) latest
+WHERE latest.creator_id = c.id;
+
+-- Keyset pagination indexes
+CREATE INDEX idx_creators_name_id ON creators(name, id);
+CREATE INDEX idx_creators_created_at_id ON creators(created_at, id);
+-- Hidden subscriber counts sort as -1, so the index is on the same expression
+-- as the listing's sort key
+CREATE INDEX idx_creators_subscriber_count_id ON creators((COALESCE(subscriber_count, -1)), id);
@@ -0,0 +1,7 @@
+-- Soft deletion and merge tracking for creators
+ALTER TABLE creators
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN merged_into_id INT REFERENCES creators(id);
+
+-- Reads skip deleted creators
+CREATE INDEX idx_creators_active ON creators(id) WHERE deleted_at IS NULL;
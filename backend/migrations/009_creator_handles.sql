@@ -0,0 +1,21 @@
+-- History of the handles each creator has used, so old handles keep resolving
+CREATE TABLE creator_handles (
+    id SERIAL PRIMARY KEY,
+    creator_id INT NOT NULL REFERENCES creators(id) ON DELETE CASCADE,
+    handle TEXT NOT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    released_at TIMESTAMPTZ -- NULL while the creator still holds the handle
+);
+
+-- Handles are case-insensitive on YouTube
+CREATE UNIQUE INDEX idx_creator_handles_creator_handle ON creator_handles(creator_id, lower(handle));
+CREATE INDEX idx_creator_handles_handle ON creator_handles(lower(handle), last_seen_at DESC);
+
+-- Make the current handle unique regardless of case
+ALTER TABLE creators DROP CONSTRAINT creators_youtube_handle_key;
+CREATE UNIQUE INDEX idx_creators_youtube_handle_lower ON creators(lower(youtube_handle));
+
+-- Seed the history with the handles currently stored
+INSERT INTO creator_handles (creator_id, handle)
+SELECT id, youtube_handle FROM creators WHERE youtube_handle IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Canonical tag slugs, see tagnorm.Slug. Existing tags get their slug, and
+-- tags that share one are merged, by db.BackfillTagSlugs when the server
+-- starts: lower() and [:alnum:] depend on the database's locale, so the slug
+-- cannot be computed here to match the one new writes use.
+ALTER TABLE tags ADD COLUMN slug TEXT;
+
+CREATE UNIQUE INDEX idx_tags_slug ON tags(slug);
@@ -0,0 +1,11 @@
+-- Alternative names that resolve to a canonical tag
+CREATE TABLE tag_aliases (
+    id SERIAL PRIMARY KEY,
+    tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    slug TEXT UNIQUE NOT NULL, -- Normalized like tags.slug
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_tag_aliases_tag_id ON tag_aliases(tag_id);
@@ -0,0 +1,10 @@
+-- Parent links between tags; together they form a DAG
+CREATE TABLE tag_parents (
+    tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    parent_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tag_id, parent_id),
+    CHECK (tag_id <> parent_id)
+);
+
+CREATE INDEX idx_tag_parents_parent_id ON tag_parents(parent_id);
@@ -0,0 +1,5 @@
+-- Trigram indexes for tag autocomplete
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_tags_name_trgm ON tags USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_tag_aliases_name_trgm ON tag_aliases USING GIN (name gin_trgm_ops);
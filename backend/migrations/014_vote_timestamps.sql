@@ -0,0 +1,9 @@
+-- When tags were attached and votes cast, for scoring and the tag list.
+-- Existing rows get the migration time.
+ALTER TABLE creator_tags ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE votes
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX idx_votes_creator_tag_id ON votes(creator_tag_id);
@@ -0,0 +1,26 @@
+-- Vote tallies per creator tag, maintained on every vote write.
+-- decayed_up and decayed_down are time-weighted sums valid at decayed_at.
+-- Rows for existing creator tags are filled in by the server on startup.
+CREATE TABLE creator_tag_scores (
+    creator_tag_id INT PRIMARY KEY REFERENCES creator_tags(id) ON DELETE CASCADE,
+    upvotes INT NOT NULL DEFAULT 0,
+    downvotes INT NOT NULL DEFAULT 0,
+    wilson DOUBLE PRECISION NOT NULL DEFAULT 0,
+    decayed_up DOUBLE PRECISION NOT NULL DEFAULT 0,
+    decayed_down DOUBLE PRECISION NOT NULL DEFAULT 0,
+    decayed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_creator_tag_scores_wilson ON creator_tag_scores(wilson DESC);
+
+-- Lower bound of the 95% Wilson score interval, mirroring scoring.Wilson,
+-- so decayed sums can be aged and scored at query time
+CREATE FUNCTION wilson_lower_bound(up DOUBLE PRECISION, down DOUBLE PRECISION)
+RETURNS DOUBLE PRECISION
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE WHEN n <= 0 THEN 0
+        ELSE GREATEST(0, (p + z * z / (2 * n) - z * sqrt((p * (1 - p) + z * z / (4 * n)) / n)) / (1 + z * z / n))
+    END
+    FROM (SELECT up + down AS n, up / NULLIF(up + down, 0) AS p, 1.959963984540054::float8 AS z) v
+$$;
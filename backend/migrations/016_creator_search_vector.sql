@@ -0,0 +1,12 @@
+-- Weighted full-text search over creator names, keywords and descriptions.
+-- A generated column stays current on every insert and refresh.
+ALTER TABLE creators ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(keywords, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_creators_search_vector ON creators USING GIN (search_vector);
+
+-- Text search also matches tag names
+CREATE INDEX idx_tags_name_tsvector ON tags USING GIN (to_tsvector('english', name));
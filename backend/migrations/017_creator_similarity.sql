@@ -0,0 +1,149 @@
+-- Precomputed similar creators, from IDF-weighted cosine similarity of
+-- vote-weighted tag vectors. Maintained by the server in the background.
+
+-- Weight of each tag on each live creator. Submitting a tag counts as one
+-- upvote; the log keeps heavily voted tags from dominating a creator's vector.
+-- Stored rather than a view so joins on tag_id can use an index; the
+-- triggers below keep it current.
+CREATE TABLE creator_tag_weights (
+    creator_id INT NOT NULL REFERENCES creators(id) ON DELETE CASCADE,
+    tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    weight DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (creator_id, tag_id)
+);
+
+CREATE INDEX idx_creator_tag_weights_tag_id ON creator_tag_weights(tag_id);
+
+INSERT INTO creator_tag_weights (creator_id, tag_id, weight)
+SELECT ct.creator_id, ct.tag_id,
+    sum(ln(1 + greatest(1 + COALESCE(s.upvotes, 0) - COALESCE(s.downvotes, 0), 0)))
+FROM creator_tags ct
+JOIN creators c ON c.id = ct.creator_id
+LEFT JOIN creator_tag_scores s ON s.creator_tag_id = ct.id
+WHERE c.deleted_at IS NULL
+GROUP BY ct.creator_id, ct.tag_id;
+
+-- Recomputes the weight of a tag on a creator, removing it once the creator
+-- is deleted or no longer has the tag
+CREATE FUNCTION refresh_creator_tag_weight(changed_creator INT, changed_tag INT) RETURNS void AS $$
+DECLARE
+    total DOUBLE PRECISION;
+BEGIN
+    -- Rows of a creator or tag being deleted go with it by cascade
+    IF NOT EXISTS (SELECT 1 FROM creators WHERE id = changed_creator)
+        OR NOT EXISTS (SELECT 1 FROM tags WHERE id = changed_tag) THEN
+        RETURN;
+    END IF;
+
+    -- Lock the row before summing, so concurrent changes to the same tag on
+    -- the same creator are summed one after the other
+    INSERT INTO creator_tag_weights (creator_id, tag_id, weight)
+    VALUES (changed_creator, changed_tag, 0)
+    ON CONFLICT (creator_id, tag_id) DO UPDATE SET weight = creator_tag_weights.weight;
+
+    SELECT sum(ln(1 + greatest(1 + COALESCE(s.upvotes, 0) - COALESCE(s.downvotes, 0), 0)))
+    INTO total
+    FROM creator_tags ct
+    JOIN creators c ON c.id = ct.creator_id
+    LEFT JOIN creator_tag_scores s ON s.creator_tag_id = ct.id
+    WHERE ct.creator_id = changed_creator AND ct.tag_id = changed_tag AND c.deleted_at IS NULL;
+
+    IF total IS NULL THEN
+        DELETE FROM creator_tag_weights WHERE creator_id = changed_creator AND tag_id = changed_tag;
+    ELSE
+        UPDATE creator_tag_weights SET weight = total
+        WHERE creator_id = changed_creator AND tag_id = changed_tag;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Inverse document frequency of each tag across live creators
+CREATE TABLE tag_idf (
+    tag_id INT PRIMARY KEY REFERENCES tags(id) ON DELETE CASCADE,
+    creators INT NOT NULL,
+    idf DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Length of each creator's weighted tag vector
+CREATE TABLE creator_vectors (
+    creator_id INT PRIMARY KEY REFERENCES creators(id) ON DELETE CASCADE,
+    norm DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE creator_neighbors (
+    creator_id INT NOT NULL REFERENCES creators(id) ON DELETE CASCADE,
+    neighbor_id INT NOT NULL REFERENCES creators(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (creator_id, neighbor_id)
+);
+
+CREATE INDEX idx_creator_neighbors_score ON creator_neighbors(creator_id, score DESC);
+CREATE INDEX idx_creator_neighbors_neighbor_id ON creator_neighbors(neighbor_id);
+
+-- Creators whose tag vectors changed since their neighbors were computed
+CREATE TABLE similarity_queue (
+    creator_id INT PRIMARY KEY REFERENCES creators(id) ON DELETE CASCADE,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE FUNCTION queue_similarity(changed INT) RETURNS void AS $$
+    INSERT INTO similarity_queue (creator_id) VALUES (changed)
+    ON CONFLICT (creator_id) DO UPDATE SET queued_at = now();
+$$ LANGUAGE sql;
+
+-- Triggers update tag weights and queue creators on every change to their
+-- tags, votes or visibility, whichever code path makes it
+CREATE FUNCTION queue_creator_tag_similarity() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM refresh_creator_tag_weight(OLD.creator_id, OLD.tag_id);
+        PERFORM queue_similarity(OLD.creator_id);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM refresh_creator_tag_weight(NEW.creator_id, NEW.tag_id);
+        PERFORM queue_similarity(NEW.creator_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER creator_tags_similarity
+AFTER INSERT OR UPDATE OF creator_id, tag_id OR DELETE ON creator_tags
+FOR EACH ROW EXECUTE FUNCTION queue_creator_tag_similarity();
+
+CREATE FUNCTION queue_tag_score_similarity() RETURNS trigger AS $$
+DECLARE
+    changed INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed := OLD.creator_tag_id;
+    ELSE
+        changed := NEW.creator_tag_id;
+    END IF;
+    -- Deletes cascading from creator_tags find no row; that trigger covers them
+    PERFORM refresh_creator_tag_weight(creator_id, tag_id), queue_similarity(creator_id)
+    FROM creator_tags WHERE id = changed;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER creator_tag_scores_similarity
+AFTER INSERT OR UPDATE OF upvotes, downvotes OR DELETE ON creator_tag_scores
+FOR EACH ROW EXECUTE FUNCTION queue_tag_score_similarity();
+
+CREATE FUNCTION queue_creator_similarity() RETURNS trigger AS $$
+BEGIN
+    PERFORM refresh_creator_tag_weight(NEW.id, tag_id)
+    FROM (SELECT DISTINCT tag_id FROM creator_tags WHERE creator_id = NEW.id) t;
+    PERFORM queue_similarity(NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER creators_similarity
+AFTER UPDATE OF deleted_at ON creators
+FOR EACH ROW WHEN (OLD.deleted_at IS DISTINCT FROM NEW.deleted_at)
+EXECUTE FUNCTION queue_creator_similarity();
@@ -0,0 +1,29 @@
+-- Positive interactions of users with live creators: upvoting one of their
+-- tags or submitting a tag. Repeated interactions count with diminishing weight.
+CREATE VIEW user_creator_interactions AS
+SELECT i.user_id, i.creator_id, ln(1 + count(*)) AS weight, max(i.at) AS last_at
+FROM (
+    SELECT v.user_id, ct.creator_id, v.updated_at AS at
+    FROM votes v
+    JOIN creator_tags ct ON ct.id = v.creator_tag_id
+    WHERE v.vote_type = 1
+    UNION ALL
+    SELECT ct.user_id, ct.creator_id, ct.created_at
+    FROM creator_tags ct
+) i
+JOIN creators c ON c.id = i.creator_id
+WHERE c.deleted_at IS NULL
+GROUP BY i.user_id, i.creator_id;
+
+-- Item-item collaborative filtering neighbors, replaced wholesale by
+-- cmd/recompute
+CREATE TABLE creator_cf_neighbors (
+    creator_id INT NOT NULL REFERENCES creators(id) ON DELETE CASCADE,
+    neighbor_id INT NOT NULL REFERENCES creators(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    support INT NOT NULL, -- Users who interacted with both creators
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (creator_id, neighbor_id)
+);
+
+CREATE INDEX idx_creator_cf_neighbors_neighbor_id ON creator_cf_neighbors(neighbor_id);
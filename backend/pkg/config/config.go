@@ -2,16 +2,21 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config structure to hold application configurations
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	OAuth    OAuthConfig
-	YouTube  YouTubeConfig
+	Server          ServerConfig
+	Database        DatabaseConfig
+	OAuth           OAuthConfig
+	YouTube         YouTubeConfig
+	Refresh         RefreshConfig
+	Scoring         ScoringConfig
+	Similarity      SimilarityConfig
+	Recommendations RecommendationsConfig
 }
 
 // ServerConfig holds server-related configurations
@@ -38,7 +43,53 @@ type OAuthConfig struct {
 
 // YouTubeConfig holds YouTube API configuration
 type YouTubeConfig struct {
-	APIKey string `mapstructure:"api_key"`
+	APIKey     string        `mapstructure:"api_key"`
+	BaseURL    string        `mapstructure:"base_url"`    // Defaults to the public Data API v3 endpoint
+	DailyQuota int           `mapstructure:"daily_quota"` // Quota units all API calls may spend per day
+	CacheTTL   time.Duration `mapstructure:"cache_ttl"`   // How long channel lookups are cached
+}
+
+// RefreshConfig controls the background creator metadata refresher
+type RefreshConfig struct {
+	Enabled      bool
+	Interval     time.Duration // How old a creator's metadata may get before it is refreshed
+	PollInterval time.Duration `mapstructure:"poll_interval"` // How often to look for stale creators
+	DailyQuota   int           `mapstructure:"daily_quota"`   // YouTube API units the refresher may spend per day
+}
+
+// ScoringConfig controls how tag votes are turned into relevance scores
+type ScoringConfig struct {
+	TimeDecay bool          `mapstructure:"time_decay"` // Rank by scores in which older votes weigh less
+	HalfLife  time.Duration `mapstructure:"half_life"`  // Age at which a vote weighs half as much
+}
+
+// SimilarityConfig controls the background similar-creator index
+type SimilarityConfig struct {
+	Enabled         bool
+	Neighbors       int           // Similar creators kept per creator
+	PollInterval    time.Duration `mapstructure:"poll_interval"`    // How often to rebuild creators whose tags changed
+	RebuildInterval time.Duration `mapstructure:"rebuild_interval"` // How often to rebuild every creator
+	BatchSize       int           `mapstructure:"batch_size"`       // Creators rebuilt per batch
+}
+
+// RecommendationsConfig controls personalized recommendations and the
+// collaborative filtering job behind them
+type RecommendationsConfig struct {
+	TagWeight       float64 `mapstructure:"tag_weight"` // Weight of tag profile similarity in the blended score
+	CFWeight        float64 `mapstructure:"cf_weight"`  // Weight of collaborative filtering in the blended score
+	Neighbors       int     // CF neighbors kept per creator
+	Shrinkage       float64 // Support at which a CF similarity counts half
+	MinCoOccurrence int     `mapstructure:"min_cooccurrence"` // Users two creators need in common to be compared
+}
+
+// DefaultRecommendations is the [recommendations] config used for settings
+// config.toml leaves out, and by tools that run without it
+var DefaultRecommendations = RecommendationsConfig{
+	TagWeight:       0.6,
+	CFWeight:        0.4,
+	Neighbors:       50,
+	Shrinkage:       10,
+	MinCoOccurrence: 2,
 }
 
 // AppConfig is the global configuration instance
@@ -53,6 +104,25 @@ func LoadConfig() {
 	// Allow environment variables to override config file values
 	viper.AutomaticEnv()
 
+	viper.SetDefault("youtube.daily_quota", 10000)
+	viper.SetDefault("youtube.cache_ttl", "6h")
+	viper.SetDefault("refresh.enabled", true)
+	viper.SetDefault("refresh.interval", "24h")
+	viper.SetDefault("refresh.poll_interval", "15m")
+	viper.SetDefault("refresh.daily_quota", 1000)
+	viper.SetDefault("scoring.time_decay", false)
+	viper.SetDefault("scoring.half_life", "2160h")
+	viper.SetDefault("similarity.enabled", true)
+	viper.SetDefault("similarity.neighbors", 50)
+	viper.SetDefault("similarity.poll_interval", "1m")
+	viper.SetDefault("similarity.rebuild_interval", "24h")
+	viper.SetDefault("similarity.batch_size", 100)
+	viper.SetDefault("recommendations.tag_weight", DefaultRecommendations.TagWeight)
+	viper.SetDefault("recommendations.cf_weight", DefaultRecommendations.CFWeight)
+	viper.SetDefault("recommendations.neighbors", DefaultRecommendations.Neighbors)
+	viper.SetDefault("recommendations.shrinkage", DefaultRecommendations.Shrinkage)
+	viper.SetDefault("recommendations.min_cooccurrence", DefaultRecommendations.MinCoOccurrence)
+
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatalf("Error reading config file: %v", err)
 	}